@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// defaultAlphabet is the digit ordering used by big.Int for bases up to 62.
+const defaultAlphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// maxStdBase is the largest base with the standard digits 0-9 and a-z,
+// in either case. Those bases always parse and print with big.Int; only
+// the extended bases above it use the alphabet.
+const maxStdBase = 36
+
+// alphabet holds the digits of the extended bases, where digit i is the
+// character at index i. digitValues is its reverse mapping.
+var (
+	alphabet    = []rune(defaultAlphabet)
+	digitValues = indexAlphabet(alphabet)
+)
+
+// SetAlphabet replaces the digit-to-value mapping of the extended bases 37
+// to 62, e.g. to follow an alternative base-62 convention. Every character
+// must be unique; whether there are enough of them is checked against each
+// base as it is used. Passing the empty string restores the default.
+func SetAlphabet(s string) error {
+	if s == "" {
+		s = defaultAlphabet
+	}
+	digits := []rune(s)
+	values := indexAlphabet(digits)
+	if len(values) != len(digits) {
+		return fmt.Errorf("alphabet %q contains duplicate characters", s)
+	}
+	alphabet, digitValues = digits, values
+	return nil
+}
+
+func indexAlphabet(digits []rune) map[rune]int {
+	values := make(map[rune]int, len(digits))
+	for i, r := range digits {
+		values[r] = i
+	}
+	return values
+}
+
+// parseBase parses s as a signed number in the given base, using the current
+// alphabet for the extended bases. A base of 0 keeps big.Int's prefix
+// auto-detection.
+func parseBase(s string, base int) (*big.Int, error) {
+	if base <= maxStdBase || string(alphabet) == defaultAlphabet {
+		y, ok := new(big.Int).SetString(s, base)
+		if !ok {
+			return nil, fmt.Errorf("invalid number")
+		}
+		return y, nil
+	}
+	if base > len(alphabet) {
+		return nil, fmt.Errorf("base %d needs %d digits but the alphabet only has %d", base, base, len(alphabet))
+	}
+
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+	if s == "" {
+		return nil, fmt.Errorf("no digits")
+	}
+
+	y := new(big.Int)
+	b := big.NewInt(int64(base))
+	for _, r := range s {
+		d, ok := digitValues[r]
+		if !ok || d >= base {
+			return nil, fmt.Errorf("invalid digit '%c' for base %d", r, base)
+		}
+		y.Mul(y, b)
+		y.Add(y, big.NewInt(int64(d)))
+	}
+	if neg {
+		y.Neg(y)
+	}
+	return y, nil
+}
+
+// basePrefixes maps the letter of a 0x, 0o or 0b prefix to its base.
+var basePrefixes = map[rune]int{'x': 16, 'X': 16, 'o': 8, 'O': 8, 'b': 2, 'B': 2}
+
+// stripBasePrefix cross-checks a 0x, 0o or 0b prefix on s against the
+// declared base: a prefix that agrees is removed, one that contradicts it
+// is an error. The letter only counts as a prefix when it is not itself a
+// digit of base, so "0b1" in base 16 is the plain number 0xb1.
+func stripBasePrefix(s string, base int) (string, error) {
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		sign, s = s[:1], s[1:]
+	}
+	runes := []rune(s)
+	if len(runes) < 2 || runes[0] != '0' {
+		return sign + s, nil
+	}
+	prefixBase, ok := basePrefixes[runes[1]]
+	if !ok {
+		return sign + s, nil
+	}
+	if _, err := parseBase(string(runes[1]), base); err == nil {
+		return sign + s, nil
+	}
+	if prefixBase != base {
+		return "", fmt.Errorf("prefix '0%c' means base %d, contradicting the declared base %d", runes[1], prefixBase, base)
+	}
+	return sign + string(runes[2:]), nil
+}
+
+// FormatBase formats n in the given base, with a leading '-' for negative
+// values. Bases up to 36 use lower-case 0-9a-z and the extended bases the
+// current alphabet, up to its size (62 by default).
+func FormatBase(n *big.Int, base int) (string, error) {
+	if base < 2 || base > max(maxStdBase, len(alphabet)) {
+		return "", fmt.Errorf("base %d out of range: must be between 2 and %d", base, max(maxStdBase, len(alphabet)))
+	}
+	if base <= maxStdBase || string(alphabet) == defaultAlphabet {
+		return n.Text(base), nil
+	}
+	if n.Sign() == 0 {
+		return string(alphabet[0]), nil
+	}
+
+	var digits []rune
+	b := big.NewInt(int64(base))
+	q := new(big.Int).Abs(n)
+	r := new(big.Int)
+	for q.Sign() > 0 {
+		q.QuoRem(q, b, r)
+		digits = append(digits, alphabet[r.Int64()])
+	}
+	if n.Sign() < 0 {
+		digits = append(digits, '-')
+	}
+	for i, j := 0, len(digits)-1; i < j; i, j = i+1, j-1 {
+		digits[i], digits[j] = digits[j], digits[i]
+	}
+	return string(digits), nil
+}
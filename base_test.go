@@ -0,0 +1,133 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+// withAlphabet installs s as the digit alphabet for the rest of the test.
+func withAlphabet(t *testing.T, s string) {
+	t.Helper()
+	if err := SetAlphabet(s); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetAlphabet("") })
+}
+
+func TestShuffledAlphabet(t *testing.T) {
+	// The default base-62 alphabet reversed, so 'Z' is 0, 'z' is 26, 'a'
+	// is 51 and '0' is 61.
+	reversed := []rune(defaultAlphabet)
+	for i, j := 0, len(reversed)-1; i < j; i, j = i+1, j-1 {
+		reversed[i], reversed[j] = reversed[j], reversed[i]
+	}
+	withAlphabet(t, string(reversed))
+	tests := []struct {
+		s    string
+		base int
+		want int64
+	}{
+		{"Z", 62, 0},
+		{"0", 62, 61},
+		{"YX", 62, 62 + 2},
+		{"-YX", 62, -(62 + 2)},
+		{"Yz", 40, 40 + 26},
+		// Bases up to 36 keep their standard digits.
+		{"9", 10, 9},
+		{"-87", 10, -87},
+		{"fa", 16, 15*16 + 10},
+	}
+	for _, tt := range tests {
+		got, err := parseBase(tt.s, tt.base)
+		if err != nil {
+			t.Errorf("parseBase(%q, %d): %v", tt.s, tt.base, err)
+			continue
+		}
+		if got.Int64() != tt.want {
+			t.Errorf("parseBase(%q, %d) = %s, want %d", tt.s, tt.base, got.String(), tt.want)
+		}
+		back, err := FormatBase(got, tt.base)
+		if err != nil || back != tt.s {
+			t.Errorf("FormatBase(%s, %d) = %q, %v, want %q", got.String(), tt.base, back, err, tt.s)
+		}
+	}
+
+	if _, err := parseBase("a", 40); err == nil {
+		t.Error("parseBase accepted a digit worth more than the base")
+	}
+	if err := SetAlphabet("0120"); err == nil {
+		t.Error("SetAlphabet accepted duplicate characters")
+	}
+}
+
+func TestShortAlphabet(t *testing.T) {
+	withAlphabet(t, "01")
+	if got, err := FormatBase(big.NewInt(255), 16); err != nil || got != "ff" {
+		t.Errorf("FormatBase(255, 16) = %q, %v, want ff", got, err)
+	}
+	if got, err := parseBase("255", 10); err != nil || got.Int64() != 255 {
+		t.Errorf("parseBase(\"255\", 10) = %v, %v, want 255", got, err)
+	}
+	if _, err := parseBase("1", 62); err == nil {
+		t.Error("parseBase accepted base 62 with a 2-character alphabet")
+	}
+	if _, err := FormatBase(big.NewInt(1), 37); err == nil {
+		t.Error("FormatBase accepted base 37 with a 2-character alphabet")
+	}
+}
+
+func TestFormatBaseRoundTrip(t *testing.T) {
+	n, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+	for _, base := range []int{2, 10, 36, 37, 50, 62} {
+		for _, v := range []*big.Int{new(big.Int), big.NewInt(61), n, new(big.Int).Neg(n)} {
+			s, err := FormatBase(v, base)
+			if err != nil {
+				t.Fatalf("FormatBase(%s, %d): %v", v.String(), base, err)
+			}
+			back, err := parseBase(s, base)
+			if err != nil {
+				t.Fatalf("parseBase(%q, %d): %v", s, base, err)
+			}
+			if back.Cmp(v) != 0 {
+				t.Errorf("base %d: %s formatted as %q parses back to %s", base, v.String(), s, back.String())
+			}
+		}
+	}
+	if got, _ := FormatBase(big.NewInt(61), 62); got != "Z" {
+		t.Errorf("FormatBase(61, 62) = %q, want Z", got)
+	}
+	if _, err := FormatBase(big.NewInt(1), 63); err == nil {
+		t.Error("FormatBase accepted base 63")
+	}
+}
+
+func TestStripBasePrefix(t *testing.T) {
+	tests := []struct {
+		s       string
+		base    int
+		want    string
+		wantErr bool
+	}{
+		{"0xff", 16, "ff", false},
+		{"-0XFF", 16, "-FF", false},
+		{"0b101", 2, "101", false},
+		{"0o17", 8, "17", false},
+		{"0xff", 10, "", true},
+		{"0o17", 16, "", true},
+		{"ff", 16, "ff", false},
+		{"0", 16, "0", false},
+		{"0b1", 16, "0b1", false}, // 'b' is a hex digit, not a prefix
+	}
+	for _, tt := range tests {
+		got, err := stripBasePrefix(tt.s, tt.base)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("stripBasePrefix(%q, %d) = %q, want a contradiction error", tt.s, tt.base, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("stripBasePrefix(%q, %d) = %q, %v, want %q", tt.s, tt.base, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// expandInputs turns the command-line arguments into the list of files to
+// process. With recursive set, every directory argument is replaced by the
+// .json files beneath it, ordered by directory and then by name so that each
+// directory's files are contiguous.
+func expandInputs(args []string, recursive bool) ([]string, error) {
+	if !recursive {
+		return args, nil
+	}
+
+	var files []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil || !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+
+		var found []string
+		err = filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && strings.EqualFold(filepath.Ext(path), ".json") {
+				found = append(found, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk %s: %w", arg, err)
+		}
+		sort.SliceStable(found, func(i, j int) bool {
+			di, dj := filepath.Dir(found[i]), filepath.Dir(found[j])
+			if di != dj {
+				return di < dj
+			}
+			return found[i] < found[j]
+		})
+		files = append(files, found...)
+	}
+	return files, nil
+}
+
+// DirGroup collects the outcomes of the files in one directory.
+type DirGroup struct {
+	Dir      string    `json:"-"`
+	Results  []Result  `json:"results"`
+	Failures []Failure `json:"failures"`
+	Solved   int       `json:"solved"`
+	Failed   int       `json:"failed"`
+}
+
+// dirGroups accumulates per-directory outcomes in the order directories are
+// first seen.
+type dirGroups struct {
+	order  []string
+	groups map[string]*DirGroup
+}
+
+// get returns the group for file's directory, creating it if needed.
+func (g *dirGroups) get(file string) *DirGroup {
+	dir := filepath.Dir(file)
+	if g.groups == nil {
+		g.groups = make(map[string]*DirGroup)
+	}
+	group, ok := g.groups[dir]
+	if !ok {
+		group = &DirGroup{Dir: dir, Results: []Result{}, Failures: []Failure{}}
+		g.groups[dir] = group
+		g.order = append(g.order, dir)
+	}
+	return group
+}
+
+// printDirHeader writes the heading that starts a directory's results.
+func printDirHeader(w io.Writer, dir string) {
+	fmt.Fprintf(w, "\n== %s ==\n", dir)
+}
+
+// printDirSummary writes the per-directory tally after its results.
+func printDirSummary(w io.Writer, g *DirGroup) {
+	fmt.Fprintf(w, "-- %s: %d solved, %d failed\n", g.Dir, g.Solved, g.Failed)
+}
+
+// windowFiles skips the first offset files and keeps at most limit of the
+// rest, for paging through a large corpus. A limit of 0 means no limit.
+func windowFiles(files []string, offset, limit int) []string {
+	if offset >= len(files) {
+		return nil
+	}
+	files = files[offset:]
+	if limit > 0 && limit < len(files) {
+		files = files[:limit]
+	}
+	return files
+}
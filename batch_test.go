@@ -0,0 +1,27 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWindowFiles(t *testing.T) {
+	files := []string{"a", "b", "c", "d", "e"}
+	tests := []struct {
+		offset, limit int
+		want          []string
+	}{
+		{0, 0, files},
+		{0, 2, []string{"a", "b"}},
+		{1, 2, []string{"b", "c"}},
+		{3, 0, []string{"d", "e"}},
+		{3, 10, []string{"d", "e"}},
+		{5, 1, nil},
+		{9, 0, nil},
+	}
+	for _, tt := range tests {
+		if got := windowFiles(files, tt.offset, tt.limit); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("windowFiles(offset=%d, limit=%d) = %v, want %v", tt.offset, tt.limit, got, tt.want)
+		}
+	}
+}
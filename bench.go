@@ -0,0 +1,100 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"runtime"
+	"sort"
+	"time"
+)
+
+// BenchReport is the outcome of solving one file repeatedly with the bench
+// subcommand.
+type BenchReport struct {
+	File       string
+	Iterations int
+	Secret     string
+	Min        time.Duration
+	Median     time.Duration
+	Max        time.Duration
+	Total      time.Duration
+	Mallocs    uint64
+	AllocBytes uint64
+}
+
+// benchFile solves file iterations times through the normal solve path
+// with opts, timing each run. The secret is taken from the last run.
+func benchFile(file string, iterations int, opts *options) (*BenchReport, error) {
+	if iterations < 1 {
+		return nil, fmt.Errorf("--iterations must be at least 1, got %d", iterations)
+	}
+
+	times := make([]time.Duration, iterations)
+	var result Result
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	for i := range times {
+		start := time.Now()
+		var err error
+		result, err = solveFile(file, opts)
+		times[i] = time.Since(start)
+		if err != nil {
+			return nil, err
+		}
+	}
+	runtime.ReadMemStats(&after)
+
+	report := &BenchReport{
+		File:       file,
+		Iterations: iterations,
+		Secret:     result.secretString(),
+		Mallocs:    after.Mallocs - before.Mallocs,
+		AllocBytes: after.TotalAlloc - before.TotalAlloc,
+	}
+	for _, t := range times {
+		report.Total += t
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	report.Min, report.Median, report.Max = times[0], times[iterations/2], times[iterations-1]
+	return report, nil
+}
+
+// printBenchReport writes the report as a short block of text to stdout.
+func printBenchReport(r *BenchReport) {
+	round := func(d time.Duration) time.Duration { return d.Round(time.Microsecond / 10) }
+	fmt.Printf("%s: %d iterations, secret %s\n", r.File, r.Iterations, r.Secret)
+	fmt.Printf("  min %v  median %v  max %v  total %v\n", round(r.Min), round(r.Median), round(r.Max), round(r.Total))
+	fmt.Printf("  %d allocations (%d per run), %d bytes allocated (%d per run)\n",
+		r.Mallocs, r.Mallocs/uint64(r.Iterations), r.AllocBytes, r.AllocBytes/uint64(r.Iterations))
+}
+
+// runBench implements the "bench" subcommand, which solves each named file
+// repeatedly and reports how long it took. Flags may come before or after
+// the file names.
+func runBench(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	iterations := fs.Int("iterations", 1000, "number of times to solve each `file`")
+
+	var files []string
+	for {
+		fs.Parse(args)
+		if fs.NArg() == 0 {
+			break
+		}
+		files = append(files, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("usage: bench FILE... [--iterations N]")
+	}
+
+	opts := &options{load: loadTestCaseDigest, method: MethodAuto}
+	for _, file := range files {
+		report, err := benchFile(file, *iterations, opts)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		printBenchReport(report)
+	}
+	return nil
+}
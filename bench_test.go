@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestBenchFileIterations(t *testing.T) {
+	file := writeFile(t, "bench.json", `{
+		"keys": {"n": 2, "k": 2},
+		"1": {"base": "10", "value": "5"},
+		"2": {"base": "10", "value": "7"}
+	}`)
+	loads := 0
+	opts := &options{method: MethodAuto, load: func(file string) (KeyInfo, []Point, string, error) {
+		loads++
+		return loadTestCaseDigest(file)
+	}}
+	report, err := benchFile(file, 7, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loads != 7 || report.Iterations != 7 {
+		t.Errorf("7 iterations: loaded %d times, report says %d", loads, report.Iterations)
+	}
+	if report.Secret != "3" {
+		t.Errorf("secret = %s, want 3", report.Secret)
+	}
+	if report.Min > report.Median || report.Median > report.Max || report.Max > report.Total {
+		t.Errorf("timings out of order: min %v median %v max %v total %v", report.Min, report.Median, report.Max, report.Total)
+	}
+
+	if _, err := benchFile(file, 0, opts); err == nil {
+		t.Error("benchFile accepted 0 iterations")
+	}
+}
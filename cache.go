@@ -0,0 +1,69 @@
+package main
+
+import (
+	"container/list"
+	"sync"
+)
+
+// solveCache is a fixed-size, least-recently-used cache of serve results,
+// keyed by the SHA-256 of the request. It is safe for concurrent use.
+type solveCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // most recently used at the front
+	entries map[string]*list.Element
+	hits    uint64
+	misses  uint64
+}
+
+type cacheEntry struct {
+	key    string
+	secret string
+}
+
+// newSolveCache returns a cache holding up to size results; a size of zero
+// or less caches nothing but still counts misses.
+func newSolveCache(size int) *solveCache {
+	return &solveCache{size: size, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// get returns the cached secret for key, if any, and records a hit or miss.
+func (c *solveCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.order.MoveToFront(e)
+		c.hits++
+		return e.Value.(*cacheEntry).secret, true
+	}
+	c.misses++
+	return "", false
+}
+
+// put stores secret under key, evicting the least recently used entry when
+// the cache is full.
+func (c *solveCache) put(key, secret string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.size <= 0 {
+		return
+	}
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*cacheEntry).secret = secret
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, secret: secret})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// stats returns the hit and miss counts and the number of cached entries.
+func (c *solveCache) stats() (hits, misses uint64, entries int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses, c.order.Len()
+}
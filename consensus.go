@@ -0,0 +1,420 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+)
+
+// maxConsensusSubsets bounds the number of k-subsets SolveByConsensus will
+// evaluate, since C(n, k) grows very quickly.
+const maxConsensusSubsets = 1 << 20
+
+// ConsensusResult is the outcome of voting over every k-subset of the shares.
+type ConsensusResult struct {
+	// Secret is the winning value, or nil when several values tie for the
+	// most votes; Candidates then lists all of them.
+	Secret     *big.Int
+	Candidates []*big.Int
+	// Votes is the number of subsets that produced Secret, out of Subsets.
+	Votes   int
+	Subsets int
+	// Confidence is Votes/Subsets: 1.0 means every subset agreed, lower
+	// values mean some shares are faulty.
+	Confidence float64
+	// Tally maps each reconstructed secret (in decimal) to its vote count.
+	// Subsets whose interpolation is not an integer cast no vote.
+	Tally map[string]int
+	// Weights is set only when some share carries a weight. It maps each
+	// secret to the summed weight of its subsets, where a subset weighs as
+	// much as its least trusted share, and the winner is then chosen by
+	// weight rather than by vote count.
+	Weights map[string]float64
+}
+
+// SolveByConsensus reconstructs the secret from every k-subset of points and
+// returns the value produced by the most subsets. With at most a few faulty
+// shares, the subsets made only of good shares outvote the rest; with too
+// many there may be no clear winner, and all tied values are returned.
+//
+// If any share has a weight, each subset votes with the minimum weight of
+// its members, so a few trusted shares can outvote many doubtful ones, and
+// Confidence becomes the winner's share of the total weight.
+func SolveByConsensus(points []Point, k int) (*ConsensusResult, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("k must be at least 1, got %d", k)
+	}
+	if len(points) < k {
+		return nil, fmt.Errorf("not enough points provided: need %d, got %d", k, len(points))
+	}
+	if count := new(big.Int).Binomial(int64(len(points)), int64(k)); !count.IsInt64() || count.Int64() > maxConsensusSubsets {
+		return nil, fmt.Errorf("too many subsets to vote over: C(%d, %d) = %s exceeds %d", len(points), k, count.String(), maxConsensusSubsets)
+	}
+
+	result := &ConsensusResult{Tally: make(map[string]int)}
+	weighted := false
+	for _, p := range points {
+		if p.Weight != 0 {
+			weighted = true
+			result.Weights = make(map[string]float64)
+			break
+		}
+	}
+	var totalWeight float64
+	secrets := make(map[string]*big.Int)
+	var order []string
+
+	var err error
+	subset := make([]Point, k)
+	combinations(len(points), k, func(idx []int) bool {
+		for i, j := range idx {
+			subset[i] = points[j]
+		}
+		var terms []LagrangeTerm
+		terms, err = lagrangeTerms(subset)
+		if err != nil {
+			return false
+		}
+		result.Subsets++
+		weight := subset[0].weight()
+		for _, p := range subset[1:] {
+			weight = math.Min(weight, p.weight())
+		}
+		totalWeight += weight
+
+		sum := terms[len(terms)-1].Sum
+		if !sum.IsInt() {
+			return true
+		}
+		key := sum.Num().String()
+		if _, seen := secrets[key]; !seen {
+			secrets[key] = new(big.Int).Set(sum.Num())
+			order = append(order, key)
+		}
+		result.Tally[key]++
+		if weighted {
+			result.Weights[key] += weight
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// score is what the election is decided on: votes, or weight.
+	score := func(key string) float64 {
+		if weighted {
+			return result.Weights[key]
+		}
+		return float64(result.Tally[key])
+	}
+
+	// Collect every secret with the top score, in the order first seen.
+	best := 0.0
+	for _, key := range order {
+		switch s := score(key); {
+		case s > best:
+			best = s
+			result.Votes = result.Tally[key]
+			result.Candidates = []*big.Int{secrets[key]}
+		case s == best:
+			result.Candidates = append(result.Candidates, secrets[key])
+		}
+	}
+	if len(result.Candidates) == 0 {
+		return nil, fmt.Errorf("no subset of %d points produced an integer secret", k)
+	}
+	if len(result.Candidates) == 1 {
+		result.Secret = result.Candidates[0]
+	}
+	result.Confidence = float64(result.Votes) / float64(result.Subsets)
+	if weighted {
+		result.Confidence = best / totalWeight
+	}
+	return result, nil
+}
+
+// combinations calls fn with each k-element subset of {0, ..., n-1}, as
+// ascending indices in lexicographic order, until fn returns false. The
+// slice passed to fn is reused between calls.
+func combinations(n, k int, fn func(idx []int) bool) {
+	if k > n || k < 0 {
+		return
+	}
+	idx := make([]int, k)
+	for i := range idx {
+		idx[i] = i
+	}
+	for {
+		if !fn(idx) {
+			return
+		}
+		i := k - 1
+		for i >= 0 && idx[i] == n-k+i {
+			i--
+		}
+		if i < 0 {
+			return
+		}
+		idx[i]++
+		for j := i + 1; j < k; j++ {
+			idx[j] = idx[j-1] + 1
+		}
+	}
+}
+
+// LocateSingleFault finds the one faulty share among points by leave-one-out
+// reconstruction: dropping the bad share leaves n-1 points on a single
+// degree-(k-1) polynomial, while dropping any good one does not. It returns
+// the index of the faulty point and the secret recovered without it.
+//
+// At least k+2 points are needed, since with k+1 every leave-one-out set is
+// trivially consistent. It errors if the points are all consistent or if no
+// single removal makes them so (more than one fault).
+func LocateSingleFault(points []Point, k int) (int, *big.Int, error) {
+	if k < 1 {
+		return 0, nil, fmt.Errorf("k must be at least 1, got %d", k)
+	}
+	if len(points) < k+2 {
+		return 0, nil, fmt.Errorf("locating a fault needs at least k+2 = %d points, got %d", k+2, len(points))
+	}
+
+	consistent := func(ps []Point) (bool, error) {
+		degree, err := MinimalDegree(ps)
+		return degree <= k, err
+	}
+
+	ok, err := consistent(points)
+	if err != nil {
+		return 0, nil, err
+	}
+	if ok {
+		return 0, nil, fmt.Errorf("no fault detected: all %d points lie on one polynomial of degree < %d", len(points), k)
+	}
+
+	fault := -1
+	rest := make([]Point, 0, len(points)-1)
+	for i := range points {
+		rest = append(rest[:0], points[:i]...)
+		rest = append(rest, points[i+1:]...)
+		ok, err := consistent(rest)
+		if err != nil {
+			return 0, nil, err
+		}
+		if !ok {
+			continue
+		}
+		if fault >= 0 {
+			return 0, nil, fmt.Errorf("ambiguous fault: removing either x=%s or x=%s makes the rest consistent", points[fault].X.String(), points[i].X.String())
+		}
+		fault = i
+	}
+	if fault < 0 {
+		return 0, nil, fmt.Errorf("more than one faulty share: no single removal makes the remaining points consistent")
+	}
+
+	rest = append(rest[:0], points[:fault]...)
+	rest = append(rest, points[fault+1:]...)
+	secret, _, err := SolveWithTrace(rest, k)
+	if err != nil {
+		return 0, nil, err
+	}
+	return fault, secret, nil
+}
+
+// ConsistencyMatrix compares the secrets of k-subsets that differ by a
+// single swap. Entry [i][j] is true when some k-subset containing share i
+// but not share j yields the same secret after i is replaced by j, meaning
+// shares i and j are interchangeable on at least one otherwise good subset.
+// The diagonal is always true.
+//
+// With one faulty share f, every swap involving f changes the secret, so
+// row and column f are false apart from the diagonal. The good shares form
+// an all-true block only when n is at least k+2, so that a swap between
+// two of them can keep k-1 other good shares; with n = k+1 every such swap
+// also keeps f, and its entry depends on how f was corrupted. Several
+// faults show up as several such rows; no swaps exist when k equals n,
+// leaving only the diagonal.
+func ConsistencyMatrix(points []Point, k int) ([][]bool, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("k must be at least 1, got %d", k)
+	}
+	if len(points) < k {
+		return nil, fmt.Errorf("not enough points provided: need %d, got %d", k, len(points))
+	}
+	if count := new(big.Int).Binomial(int64(len(points)), int64(k)); !count.IsInt64() || count.Int64() > maxConsensusSubsets {
+		return nil, fmt.Errorf("too many subsets to compare: C(%d, %d) = %s exceeds %d", len(points), k, count.String(), maxConsensusSubsets)
+	}
+
+	n := len(points)
+	subsetKey := func(idx []int) string { return fmt.Sprint(idx) }
+
+	// The secret of every k-subset, as a fraction so that non-integer
+	// results still compare.
+	secrets := make(map[string]string)
+	var subsets [][]int
+	var err error
+	subset := make([]Point, k)
+	combinations(n, k, func(idx []int) bool {
+		for i, j := range idx {
+			subset[i] = points[j]
+		}
+		var terms []LagrangeTerm
+		terms, err = lagrangeTerms(subset)
+		if err != nil {
+			return false
+		}
+		secrets[subsetKey(idx)] = terms[len(terms)-1].Sum.RatString()
+		subsets = append(subsets, append([]int(nil), idx...))
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	matrix := make([][]bool, n)
+	for i := range matrix {
+		matrix[i] = make([]bool, n)
+		matrix[i][i] = true
+	}
+
+	swapped := make([]int, k)
+	for _, idx := range subsets {
+		in := make(map[int]bool, k)
+		for _, i := range idx {
+			in[i] = true
+		}
+		for pos, i := range idx {
+			for j := 0; j < n; j++ {
+				if in[j] || matrix[i][j] {
+					continue
+				}
+				copy(swapped, idx)
+				swapped[pos] = j
+				sort.Ints(swapped)
+				if secrets[subsetKey(swapped)] == secrets[subsetKey(idx)] {
+					matrix[i][j] = true
+					matrix[j][i] = true
+				}
+			}
+		}
+	}
+	return matrix, nil
+}
+
+// SecretSupport reconstructs the secret from every k-subset of points and
+// groups the subsets by the secret they produced. Each key is a secret in
+// decimal, or "a/b" when a subset's interpolation is not an integer, and
+// maps to the x-coordinates of every subset that produced it, in
+// lexicographic subset order. This is the full structure behind
+// SolveByConsensus: with several faults it shows every competing value
+// and exactly which shares back it.
+func SecretSupport(points []Point, k int) (map[string][][]string, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("k must be at least 1, got %d", k)
+	}
+	if len(points) < k {
+		return nil, fmt.Errorf("not enough points provided: need %d, got %d", k, len(points))
+	}
+	if count := new(big.Int).Binomial(int64(len(points)), int64(k)); !count.IsInt64() || count.Int64() > maxConsensusSubsets {
+		return nil, fmt.Errorf("too many subsets to examine: C(%d, %d) = %s exceeds %d", len(points), k, count.String(), maxConsensusSubsets)
+	}
+
+	support := make(map[string][][]string)
+	var err error
+	subset := make([]Point, k)
+	combinations(len(points), k, func(idx []int) bool {
+		xs := make([]string, k)
+		for i, j := range idx {
+			subset[i] = points[j]
+			xs[i] = points[j].X.String()
+		}
+		var terms []LagrangeTerm
+		terms, err = lagrangeTerms(subset)
+		if err != nil {
+			return false
+		}
+		key := terms[len(terms)-1].Sum.RatString()
+		support[key] = append(support[key], xs)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return support, nil
+}
+
+// DetectSplit clusters points by the degree-(k-1) polynomial they lie on,
+// to diagnose files that mix the shares of different schemes. It
+// repeatedly takes the polynomial through some k of the remaining points
+// that passes through the most of them, ties going to the first subset in
+// lexicographic order, and removes those points as one group. Each group
+// is a list of indices into points in ascending order, largest group
+// first; a single group means every point is on one polynomial.
+//
+// When fewer than k points are left over they form a last group of their
+// own, since any k-1 points fit some polynomial and so cannot be told
+// apart.
+func DetectSplit(points []Point, k int) ([][]int, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("k must be at least 1, got %d", k)
+	}
+	if len(points) < k {
+		return nil, fmt.Errorf("not enough points provided: need %d, got %d", k, len(points))
+	}
+	if count := new(big.Int).Binomial(int64(len(points)), int64(k)); !count.IsInt64() || count.Int64() > maxConsensusSubsets {
+		return nil, fmt.Errorf("too many subsets to cluster: C(%d, %d) = %s exceeds %d", len(points), k, count.String(), maxConsensusSubsets)
+	}
+
+	remaining := make([]int, len(points))
+	for i := range remaining {
+		remaining[i] = i
+	}
+	var groups [][]int
+	var err error
+	subset := make([]Point, k)
+	for len(remaining) >= k {
+		var best []int
+		combinations(len(remaining), k, func(idx []int) bool {
+			for i, j := range idx {
+				subset[i] = points[remaining[j]]
+			}
+			var members []int
+			for _, i := range remaining {
+				var fx *big.Rat
+				fx, err = lagrangeEval(subset, points[i].X)
+				if err != nil {
+					return false
+				}
+				if fx.IsInt() && fx.Num().Cmp(points[i].Y) == 0 {
+					members = append(members, i)
+				}
+			}
+			if len(members) > len(best) {
+				best = members
+			}
+			return len(best) < len(remaining)
+		})
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, best)
+
+		in := make(map[int]bool, len(best))
+		for _, i := range best {
+			in[i] = true
+		}
+		rest := remaining[:0]
+		for _, i := range remaining {
+			if !in[i] {
+				rest = append(rest, i)
+			}
+		}
+		remaining = rest
+	}
+	if len(remaining) > 0 {
+		groups = append(groups, remaining)
+	}
+	return groups, nil
+}
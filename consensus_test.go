@@ -0,0 +1,179 @@
+package main
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestLocateSingleFault(t *testing.T) {
+	points := polyPoints([]int64{3, 2, 1}, 1, 2, 3, 4, 5, 6)
+	points[3].Y = new(big.Int).Add(points[3].Y, big.NewInt(1))
+
+	fault, secret, err := LocateSingleFault(points, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fault != 3 {
+		t.Errorf("fault = %d, want 3", fault)
+	}
+	if secret.Int64() != 3 {
+		t.Errorf("secret = %s, want 3", secret.String())
+	}
+
+	if _, _, err := LocateSingleFault(polyPoints([]int64{3, 2, 1}, 1, 2, 3, 4, 5, 6), 3); err == nil {
+		t.Error("LocateSingleFault reported a fault in consistent points")
+	}
+}
+
+func TestConsensusAmbiguous(t *testing.T) {
+	// Two lines with three shares each: every pair within a line agrees,
+	// so 3 and 10 tie with three votes apiece.
+	points := append(polyPoints([]int64{3, 2}, 1, 2, 3), polyPoints([]int64{10, 1}, 4, 5, 6)...)
+	result, err := SolveByConsensus(points, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Secret != nil {
+		t.Fatalf("Secret = %s, want nil for a tie", result.Secret.String())
+	}
+	if len(result.Candidates) != 2 || result.Candidates[0].Int64() != 3 || result.Candidates[1].Int64() != 10 {
+		t.Fatalf("Candidates = %v, want [3 10]", result.Candidates)
+	}
+	if result.Votes != 3 {
+		t.Errorf("Votes = %d, want 3", result.Votes)
+	}
+}
+
+func TestConsistencyMatrixSingleFault(t *testing.T) {
+	points := polyPoints([]int64{3, 2, 1}, 1, 2, 3, 4, 5, 6)
+	points[2].Y = new(big.Int).Add(points[2].Y, big.NewInt(1))
+
+	matrix, err := ConsistencyMatrix(points, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range matrix {
+		for j := range matrix[i] {
+			want := i == j || (i != 2 && j != 2)
+			if matrix[i][j] != want {
+				t.Errorf("matrix[%d][%d] = %v, want %v", i, j, matrix[i][j], want)
+			}
+		}
+	}
+}
+
+func TestConsensusWeighted(t *testing.T) {
+	// Three trusted shares on one line against four doubtful ones on
+	// another: the four have more pairs, the three more weight.
+	trusted := polyPoints([]int64{100, 7}, 1, 2, 3)
+	for i := range trusted {
+		trusted[i].Weight = 10
+	}
+	doubtful := polyPoints([]int64{10, 1}, 4, 5, 6, 7)
+	for i := range doubtful {
+		doubtful[i].Weight = 1
+	}
+	points := append(trusted, doubtful...)
+
+	result, err := SolveByConsensus(points, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Secret == nil || result.Secret.Int64() != 100 {
+		t.Fatalf("weighted winner = %v, want 100", result.Secret)
+	}
+	if result.Tally["10"] <= result.Tally["100"] {
+		t.Errorf("tally = %v: the low-weight group should have more votes", result.Tally)
+	}
+
+	for i := range points {
+		points[i].Weight = 0
+	}
+	result, err = SolveByConsensus(points, 2)
+	if err != nil || result.Secret == nil || result.Secret.Int64() != 10 {
+		t.Errorf("unweighted winner = %+v, %v, want 10", result, err)
+	}
+}
+
+func TestInjectFaultDetected(t *testing.T) {
+	// f(x) = 3 + 2x + x^2 at x = 1..5.
+	file := writeFile(t, "clean.json", `{
+		"keys": {"n": 5, "k": 3},
+		"1": {"base": "10", "value": "6"},
+		"2": {"base": "10", "value": "11"},
+		"3": {"base": "10", "value": "18"},
+		"4": {"base": "10", "value": "27"},
+		"5": {"base": "10", "value": "38"}
+	}`)
+	opts := &options{load: loadTestCaseDigest, consensus: true}
+	clean, err := solveFile(file, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *clean.Confidence != 1 {
+		t.Fatalf("confidence without a fault = %v, want 1", *clean.Confidence)
+	}
+
+	opts.injectFault = big.NewInt(4)
+	faulty, err := solveFile(file, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if faulty.Secret.Int64() != 3 {
+		t.Errorf("secret with a fault at x=4 = %s, want 3", faulty.Secret.String())
+	}
+	if *faulty.Confidence >= 1 {
+		t.Errorf("confidence with a fault at x=4 = %v, want below 1", *faulty.Confidence)
+	}
+}
+
+func TestSecretSupportTwoFaults(t *testing.T) {
+	// f(x) = 3 + 2x, except that the shares at x=5 and x=6 were replaced
+	// by points on 10 + x, so that pair backs a second secret.
+	points := polyPoints([]int64{3, 2}, 1, 2, 3, 4, 5, 6)
+	points[4].Y, points[5].Y = big.NewInt(15), big.NewInt(16)
+
+	support, err := SecretSupport(points, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for _, subsets := range support {
+		total += len(subsets)
+	}
+	if total != 15 {
+		t.Errorf("%d subsets in total, want C(6, 2) = 15", total)
+	}
+	want := [][]string{{"1", "2"}, {"1", "3"}, {"1", "4"}, {"2", "3"}, {"2", "4"}, {"3", "4"}}
+	if !reflect.DeepEqual(support["3"], want) {
+		t.Errorf("support for 3 = %v, want %v", support["3"], want)
+	}
+	if !reflect.DeepEqual(support["10"], [][]string{{"5", "6"}}) {
+		t.Errorf("support for 10 = %v, want [[5 6]]", support["10"])
+	}
+	if !reflect.DeepEqual(support["5/2"], [][]string{{"1", "5"}, {"2", "6"}}) {
+		t.Errorf("support for 5/2 = %v, want [[1 5] [2 6]]", support["5/2"])
+	}
+}
+
+func TestDetectSplit(t *testing.T) {
+	// Four shares of 3 + 2x + x^2 followed by three of 10 - x + 2x^2.
+	points := append(polyPoints([]int64{3, 2, 1}, 1, 2, 3, 4), polyPoints([]int64{10, -1, 2}, 5, 6, 7)...)
+	groups, err := DetectSplit(points, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]int{{0, 1, 2, 3}, {4, 5, 6}}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("DetectSplit = %v, want %v", groups, want)
+	}
+
+	groups, err = DetectSplit(points[:4], 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(groups, [][]int{{0, 1, 2, 3}}) {
+		t.Errorf("DetectSplit on one polynomial = %v, want a single group", groups)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// csvColumns are the header names a CSV share file must have, in any order.
+var csvColumns = []string{"x", "base", "value"}
+
+// isCSVInput reports whether file should be read with loadCSVTestCase:
+// always with --input-format csv, and by its .csv extension otherwise.
+func isCSVInput(file, inputFormat string) bool {
+	switch inputFormat {
+	case "csv":
+		return true
+	case "json":
+		return false
+	}
+	return strings.EqualFold(filepath.Ext(file), ".csv")
+}
+
+// loadCSVTestCase reads shares from a CSV file with a header row naming
+// the x, base and value columns. CSV has no 'keys' object, so k comes from
+// --k and n from --n, defaulting to the number of rows. The hex SHA-256 of
+// the file is returned alongside.
+func loadCSVTestCase(filePath string, k, n int) (KeyInfo, []Point, string, error) {
+	if k < 1 {
+		return KeyInfo{}, nil, "", fmt.Errorf("--k must be at least 1 when reading CSV shares, got %d", k)
+	}
+
+	data, err := readFileRetry(filePath)
+	if err != nil {
+		return KeyInfo{}, nil, "", fmt.Errorf("failed to read file %s: %w", filePath, err)
+	}
+	points, err := parseCSVShares(bytes.NewReader(data))
+	if err != nil {
+		return KeyInfo{}, nil, "", fmt.Errorf("failed to parse CSV in %s: %w", filePath, err)
+	}
+
+	if n == 0 {
+		n = len(points)
+	}
+	if len(points) < k {
+		return KeyInfo{}, nil, "", fmt.Errorf("not enough points provided: need %d, got %d", k, len(points))
+	}
+	if !documentOrder {
+		sort.SliceStable(points, func(i, j int) bool { return points[i].X.Cmp(points[j].X) < 0 })
+	}
+	return KeyInfo{N: n, K: k}, points, hashBytes(data), nil
+}
+
+// parseCSVShares decodes every row of r into a point, in file order.
+func parseCSVShares(r io.Reader) ([]Point, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("missing header row")
+	}
+	if err != nil {
+		return nil, err
+	}
+	column := make(map[string]int)
+	for i, name := range header {
+		column[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range csvColumns {
+		if _, ok := column[name]; !ok {
+			return nil, fmt.Errorf("header has no '%s' column", name)
+		}
+	}
+
+	var points []Point
+	seen := make(map[string]string)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		xStr := strings.TrimSpace(record[column["x"]])
+		x, ok := new(big.Int).SetString(xStr, 10)
+		if !ok {
+			return nil, fmt.Errorf("line %d: failed to parse x-coordinate '%s' to integer", line, xStr)
+		}
+		if err := checkBits("x-coordinate", xStr, x); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := checkDuplicateX(seen, xStr, x); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rootVal := RootValue{
+			Base:  strings.TrimSpace(record[column["base"]]),
+			Value: strings.TrimSpace(record[column["value"]]),
+		}
+		y, err := decodeY(xStr, rootVal)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		points = append(points, Point{X: x, Y: y})
+	}
+	return points, nil
+}
@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestLoadCSVQuotedValue(t *testing.T) {
+	// f(x) = 3 + 2x; columns in any order, values quoted or not.
+	file := writeFile(t, "shares.csv", "value,x,base\n"+
+		"\"5\",1,10\n"+
+		"\" 111 \",2,2\n"+
+		"\"9\",3,\"16\"\n")
+	keys, points, _, err := loadCSVTestCase(file, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys.N != 3 {
+		t.Errorf("n = %d, want the row count 3", keys.N)
+	}
+	if points[1].Y.Int64() != 7 {
+		t.Errorf("quoted binary value decoded to %s, want 7", points[1].Y.String())
+	}
+	secret, _, err := SolveWithTrace(points, keys.K)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Int64() != 3 {
+		t.Fatalf("secret = %s, want 3", secret.String())
+	}
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// decoder turns the root object of the share at keyStr into its y-value.
+type decoder func(keyStr string, rootVal RootValue) (*big.Int, error)
+
+// decoders maps a share's "encoding" field to the function that decodes it.
+// The empty encoding is the ordinary positional base-N notation.
+var decoders = map[string]decoder{
+	"":         decodePositional,
+	"balanced": decodeBalanced,
+	"hexbytes": decodeByteString,
+	"base64":   decodeByteString,
+}
+
+// allowedBases is the set of bases shares may declare, set by
+// --allowed-bases. A nil set allows every base.
+var allowedBases map[int]bool
+
+// SetAllowedBases restricts the bases shares may declare to the
+// comma-separated list s, such as "10,16". The empty string lifts the
+// restriction.
+func SetAllowedBases(s string) error {
+	if s == "" {
+		allowedBases = nil
+		return nil
+	}
+	set := make(map[int]bool)
+	for _, field := range strings.Split(s, ",") {
+		base, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || base < 2 {
+			return fmt.Errorf("invalid base '%s' in list", field)
+		}
+		set[base] = true
+	}
+	allowedBases = set
+	return nil
+}
+
+// maxBits caps the bit length of any decoded coordinate, set by --max-bits,
+// so that one enormous value cannot make interpolation crawl. Zero means no
+// limit.
+var maxBits = 0
+
+// checkBits reports an error if n, the named coordinate of the share at
+// keyStr, is longer than maxBits.
+func checkBits(name, keyStr string, n *big.Int) error {
+	if maxBits > 0 && n.BitLen() > maxBits {
+		return fmt.Errorf("%s for key '%s' is %d bits, over the --max-bits limit of %d", name, keyStr, n.BitLen(), maxBits)
+	}
+	return nil
+}
+
+// decodeY turns the root object of the share at keyStr into its y-value.
+func decodeY(keyStr string, rootVal RootValue) (*big.Int, error) {
+	y, err := decodeYValue(keyStr, rootVal)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkBits("y-value", keyStr, y); err != nil {
+		return nil, err
+	}
+	return y, nil
+}
+
+// decodeYValue is decodeY without the --max-bits check.
+func decodeYValue(keyStr string, rootVal RootValue) (*big.Int, error) {
+	if allowedBases != nil && rootVal.Base != "" {
+		base, err := strconv.Atoi(rootVal.Base)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base '%s' for key '%s'", rootVal.Base, keyStr)
+		}
+		if !allowedBases[base] {
+			return nil, fmt.Errorf("base %d for key '%s' is not in --allowed-bases", base, keyStr)
+		}
+	}
+	if rootVal.Factors != nil {
+		return decodeFactors(keyStr, rootVal.Factors)
+	}
+
+	decode, ok := decoders[rootVal.Encoding]
+	if !ok {
+		return nil, fmt.Errorf("unknown encoding '%s' for key '%s'", rootVal.Encoding, keyStr)
+	}
+	return decode(keyStr, rootVal)
+}
+
+// shareWeight returns the share's "weight", or 0 if it has none. A weight
+// must be a positive finite number.
+func shareWeight(keyStr string, rootVal RootValue) (float64, error) {
+	if rootVal.Weight == nil {
+		return 0, nil
+	}
+	w := *rootVal.Weight
+	if !(w > 0) || math.IsInf(w, 1) {
+		return 0, fmt.Errorf("invalid weight %v for key '%s': must be a positive number", w, keyStr)
+	}
+	return w, nil
+}
+
+// preserveWidth makes shareWidth record byte widths, set by
+// --preserve-width. Byte strings are then told apart by length as well as
+// value, so "00ff" is two bytes wide where "ff" is one.
+var preserveWidth bool
+
+// shareWidth returns the byte length of a hexbytes or base64 share when
+// preserveWidth is set, and 0 otherwise. Positional values have no width:
+// their leading zeros never matter.
+func shareWidth(keyStr string, rootVal RootValue) (int, error) {
+	if !preserveWidth || (rootVal.Encoding != "hexbytes" && rootVal.Encoding != "base64") {
+		return 0, nil
+	}
+	b, err := shareBytes(keyStr, rootVal)
+	if err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// decodePositional parses Value as a standard base-N number.
+func decodePositional(keyStr string, rootVal RootValue) (*big.Int, error) {
+	base, err := strconv.Atoi(rootVal.Base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base '%s' for key '%s'", rootVal.Base, keyStr)
+	}
+
+	value := rootVal.Value
+	if base != 0 {
+		if value, err = stripBasePrefix(value, base); err != nil {
+			return nil, fmt.Errorf("y-value '%s' for key '%s': %v", rootVal.Value, keyStr, err)
+		}
+	}
+
+	y, err := parseBase(value, base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse y-value '%s' in base %d for key '%s': %v", rootVal.Value, base, keyStr, err)
+	}
+	if roundtripCheck && base != 0 {
+		if err := checkRoundtrip(keyStr, rootVal.Value, value, y, base); err != nil {
+			return nil, err
+		}
+	}
+	return y, nil
+}
+
+// roundtripCheck makes decodePositional re-encode each y-value in its
+// declared base and warn if the result differs from the value as written,
+// set by --roundtrip-check.
+var roundtripCheck bool
+
+// checkRoundtrip formats y back in base and compares it with value, the
+// share's digits after any base prefix was removed, ignoring leading zeros
+// and, in bases up to 36, case. Above 36 case tells digits apart, so it must
+// match. A mismatch, such as a leading '+' or a digit the parser and
+// FormatBase disagree about, is reported through warnf.
+func checkRoundtrip(keyStr, original, value string, y *big.Int, base int) error {
+	encoded, err := FormatBase(y, base)
+	if err != nil {
+		return err
+	}
+	got, want := trimZeros(value, base), trimZeros(encoded, base)
+	if got != want && !(base <= maxStdBase && strings.EqualFold(got, want)) {
+		return warnf("y-value '%s' for key '%s' does not round-trip: it decodes to %s, which base %d writes as '%s'", original, keyStr, y.String(), base, encoded)
+	}
+	return nil
+}
+
+// trimZeros drops the leading zero digits of s after any '-' sign, keeping
+// a single zero if that is all there is.
+func trimZeros(s string, base int) string {
+	zero := "0"
+	if base > maxStdBase {
+		zero = string(alphabet[0])
+	}
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	if t := strings.TrimLeft(s, zero); t != "" || s == "" {
+		return sign + t
+	}
+	return sign + zero
+}
+
+// maxFactorBits bounds the size of a y-value given as a factorization.
+// Each power is sized up before it is computed, since a share such as
+// [[3,200000000]] would otherwise take a minute of CPU to expand.
+const maxFactorBits = 1 << 20
+
+// decodeFactors computes y from a factorization such as [[2,3],[5,1]],
+// which stands for 2^3 * 5^1. An empty list is the empty product, 1.
+func decodeFactors(keyStr string, factors [][]json.Number) (*big.Int, error) {
+	y := big.NewInt(1)
+	// bits is a lower bound on the bit length of y once every factor so
+	// far is multiplied in: base^exp has more than exp*(BitLen(base)-1).
+	bits := new(big.Int)
+	limit := big.NewInt(maxFactorBits)
+	capped := maxBits > 0 && maxBits < maxFactorBits
+	if capped {
+		limit = big.NewInt(int64(maxBits))
+	}
+	for i, pair := range factors {
+		if len(pair) != 2 {
+			return nil, fmt.Errorf("factor %d for key '%s' must be a [base, exponent] pair, got %d values", i, keyStr, len(pair))
+		}
+
+		base, ok := new(big.Int).SetString(pair[0].String(), 10)
+		if !ok || base.Sign() < 0 {
+			return nil, fmt.Errorf("factor %d for key '%s' has invalid base '%s': must be a non-negative integer", i, keyStr, pair[0])
+		}
+		exp, ok := new(big.Int).SetString(pair[1].String(), 10)
+		if !ok || exp.Sign() < 0 {
+			return nil, fmt.Errorf("factor %d for key '%s' has invalid exponent '%s': must be a non-negative integer", i, keyStr, pair[1])
+		}
+
+		if base.BitLen() > 1 {
+			bits.Add(bits, new(big.Int).Mul(exp, big.NewInt(int64(base.BitLen()-1))))
+			if bits.Cmp(limit) > 0 {
+				if capped {
+					return nil, fmt.Errorf("factors for key '%s' give a y-value of over %d bits, the --max-bits limit", keyStr, maxBits)
+				}
+				return nil, fmt.Errorf("factors for key '%s' give a y-value of over %d bits", keyStr, maxFactorBits)
+			}
+		}
+		y.Mul(y, new(big.Int).Exp(base, exp, nil))
+	}
+	return y, nil
+}
+
+// decodeBalanced parses Value as a balanced ternary number, where each digit
+// is -1, 0 or 1. The digit -1 is written as 'T' or '-', so "1T" is 3-1 = 2
+// and "T0" is -3.
+func decodeBalanced(keyStr string, rootVal RootValue) (*big.Int, error) {
+	if rootVal.Base != "3" {
+		return nil, fmt.Errorf("balanced encoding only supports base 3, got base '%s' for key '%s'", rootVal.Base, keyStr)
+	}
+	if rootVal.Value == "" {
+		return nil, fmt.Errorf("empty balanced ternary value for key '%s'", keyStr)
+	}
+
+	y := new(big.Int)
+	three := big.NewInt(3)
+	for _, c := range rootVal.Value {
+		var digit int64
+		switch c {
+		case '0':
+			digit = 0
+		case '1':
+			digit = 1
+		case 'T', 't', '-':
+			digit = -1
+		default:
+			return nil, fmt.Errorf("invalid balanced ternary digit '%c' in value '%s' for key '%s'", c, rootVal.Value, keyStr)
+		}
+		y.Mul(y, three)
+		y.Add(y, big.NewInt(digit))
+	}
+	return y, nil
+}
+
+// decodeByteString reads a hexbytes or base64 value as an unsigned
+// big-endian integer.
+func decodeByteString(keyStr string, rootVal RootValue) (*big.Int, error) {
+	b, err := shareBytes(keyStr, rootVal)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// shareBytes returns the raw bytes of a share stored with the hexbytes or
+// base64 encoding, keeping any leading zero bytes.
+func shareBytes(keyStr string, rootVal RootValue) ([]byte, error) {
+	var b []byte
+	var err error
+	switch rootVal.Encoding {
+	case "hexbytes":
+		b, err = hex.DecodeString(rootVal.Value)
+	case "base64":
+		b, err = base64.StdEncoding.DecodeString(rootVal.Value)
+	default:
+		return nil, fmt.Errorf("share for key '%s' is not a byte string: encoding must be hexbytes or base64, got '%s'", keyStr, rootVal.Encoding)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s value '%s' for key '%s': %v", rootVal.Encoding, rootVal.Value, keyStr, err)
+	}
+	return b, nil
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDecodeFactors(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{`{"factors": [[2, 3], [5, 1]]}`, "40"},
+		{`{"factors": [[3, 4], [7, 2], [11, 0]]}`, "3969"},
+		{`{"factors": []}`, "1"},
+	}
+	for _, tt := range tests {
+		p, err := decodeShare("1", json.RawMessage(tt.raw))
+		if err != nil {
+			t.Errorf("decodeShare(%s): %v", tt.raw, err)
+			continue
+		}
+		if got := p.Y.String(); got != tt.want {
+			t.Errorf("decodeShare(%s) = %s, want %s", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeFactorsTooLarge(t *testing.T) {
+	_, err := decodeShare("1", json.RawMessage(`{"factors": [[3, 200000000]]}`))
+	if err == nil || !strings.Contains(err.Error(), "bits") {
+		t.Fatalf("decodeShare with a huge exponent: got %v, want a size error", err)
+	}
+}
+
+func TestDecodeBalanced(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"1", "1"},
+		{"1T", "2"},
+		{"T0", "-3"},
+		{"1TT", "5"},
+		{"1-1", "7"},
+		{"0", "0"},
+	}
+	for _, tt := range tests {
+		y, err := decodeBalanced("1", RootValue{Base: "3", Value: tt.value, Encoding: "balanced"})
+		if err != nil {
+			t.Errorf("decodeBalanced(%q): %v", tt.value, err)
+			continue
+		}
+		if y.String() != tt.want {
+			t.Errorf("decodeBalanced(%q) = %s, want %s", tt.value, y.String(), tt.want)
+		}
+	}
+}
+
+func TestSolveBalanced(t *testing.T) {
+	// f(x) = 3 + 2x, with f(1) = 5 and f(2) = 7 in balanced ternary.
+	file := writeFile(t, "balanced.json", `{
+		"keys": {"n": 2, "k": 2},
+		"1": {"base": "3", "encoding": "balanced", "value": "1TT"},
+		"2": {"base": "3", "encoding": "balanced", "value": "1T1"}
+	}`)
+	secret, err := solveForSecret(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.String() != "3" {
+		t.Fatalf("secret = %s, want 3", secret.String())
+	}
+}
+
+func TestAllowedBases(t *testing.T) {
+	if err := SetAllowedBases("10, 16"); err != nil {
+		t.Fatal(err)
+	}
+	defer SetAllowedBases("")
+
+	if _, err := decodeY("1", RootValue{Base: "16", Value: "ff"}); err != nil {
+		t.Errorf("base 16 rejected: %v", err)
+	}
+	_, err := decodeY("2", RootValue{Base: "8", Value: "17"})
+	if err == nil || !strings.Contains(err.Error(), "base 8") {
+		t.Errorf("base 8 share: got %v, want a not-allowed error", err)
+	}
+	if err := SetAllowedBases("10,x"); err == nil {
+		t.Error("SetAllowedBases accepted a non-numeric base")
+	}
+}
+
+// withMaxBits sets --max-bits for the rest of the test.
+func withMaxBits(t *testing.T, bits int) {
+	t.Helper()
+	maxBits = bits
+	t.Cleanup(func() { maxBits = 0 })
+}
+
+func TestMaxBits(t *testing.T) {
+	withMaxBits(t, 64)
+
+	if _, err := decodeY("1", RootValue{Base: "16", Value: "ffffffffffffffff"}); err != nil {
+		t.Errorf("64-bit value rejected: %v", err)
+	}
+	_, err := decodeY("1", RootValue{Base: "16", Value: "1ffffffffffffffff"})
+	if err == nil || !strings.Contains(err.Error(), "--max-bits") {
+		t.Errorf("65-bit value: got %v, want a --max-bits error", err)
+	}
+
+	// Rejected from its size estimate, without computing 3^200000000.
+	_, err = decodeShare("1", json.RawMessage(`{"factors": [[3, 200000000]]}`))
+	if err == nil || !strings.Contains(err.Error(), "--max-bits") {
+		t.Errorf("huge factorization: got %v, want a --max-bits error", err)
+	}
+	if _, err := decodeShare("1", json.RawMessage(`{"factors": [[2, 63]]}`)); err != nil {
+		t.Errorf("2^63 rejected: %v", err)
+	}
+}
+
+func TestPreserveWidth(t *testing.T) {
+	decode := func() (int, int) {
+		t.Helper()
+		a, err := decodeShare("1", json.RawMessage(`{"encoding": "hexbytes", "value": "00ff"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := decodeShare("2", json.RawMessage(`{"encoding": "hexbytes", "value": "ff"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.Y.Cmp(b.Y) != 0 {
+			t.Errorf("00ff decodes to %s and ff to %s, want equal values", a.Y.String(), b.Y.String())
+		}
+		return a.Width, b.Width
+	}
+
+	if a, b := decode(); a != 0 || b != 0 {
+		t.Errorf("without --preserve-width: widths %d and %d, want 0 and 0", a, b)
+	}
+	preserveWidth = true
+	defer func() { preserveWidth = false }()
+	if a, b := decode(); a != 2 || b != 1 {
+		t.Errorf("with --preserve-width: widths %d and %d, want 2 and 1", a, b)
+	}
+}
+
+func TestRoundtripCheck(t *testing.T) {
+	roundtripCheck = true
+	defer func() { roundtripCheck = false }()
+
+	tests := []struct {
+		base, value string
+		canonical   bool
+	}{
+		{"16", "ff", true},
+		{"16", "FF", true},
+		{"16", "00ff", true},
+		{"16", "0xFF", true},
+		{"10", "-007", true},
+		{"10", "0", true},
+		{"62", "aZ", true},
+		{"62", "0aZ", true},
+		{"10", "+100", false},
+		{"16", "+00FF", false},
+		{"10", "-0", false},
+		{"62", "+aZ", false},
+	}
+	for _, tt := range tests {
+		share := RootValue{Base: tt.base, Value: tt.value}
+		if _, err := decodeY("1", share); err != nil {
+			t.Errorf("base %s %q without --strict: %v, want only a warning", tt.base, tt.value, err)
+		}
+		strict = true
+		_, err := decodeY("1", share)
+		strict = false
+		if tt.canonical && err != nil {
+			t.Errorf("base %s %q: %v, want no warning", tt.base, tt.value, err)
+		}
+		if !tt.canonical && (err == nil || !strings.Contains(err.Error(), "does not round-trip")) {
+			t.Errorf("base %s %q under --strict: got %v, want a round-trip error", tt.base, tt.value, err)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ParsedShare is one share as --dry-parse reports it: where it came from
+// and what it decoded to.
+type ParsedShare struct {
+	X        string   `json:"x"`
+	Base     string   `json:"base,omitempty"`
+	Encoding string   `json:"encoding,omitempty"`
+	Weight   *float64 `json:"weight,omitempty"`
+	DecodedY string   `json:"decoded_y"`
+	Width    int      `json:"width,omitempty"`
+}
+
+// ParsedTestCase is a test case as the loader interpreted it, before any
+// solving.
+type ParsedTestCase struct {
+	File   string        `json:"file"`
+	Keys   KeyInfo       `json:"keys"`
+	Points []ParsedShare `json:"points"`
+}
+
+// dryParse loads file the way loadTestCase does and reports each share's
+// declared base next to its decoded y, so a wrong base shows up at a glance.
+// Under --stop-at-k only the shares that were decoded are reported.
+func dryParse(file string) (*ParsedTestCase, error) {
+	keys, rawData, orderedKeys, err := readTestCase(file)
+	if err != nil {
+		return nil, err
+	}
+	points, err := decodePoints(keys, rawData, orderedKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := &ParsedTestCase{File: file, Keys: keys, Points: make([]ParsedShare, len(points))}
+	for i, keyStr := range orderedKeys[:len(points)] {
+		var rootVal RootValue
+		if err := json.Unmarshal(rawData[keyStr], &rootVal); err != nil {
+			return nil, fmt.Errorf("failed to parse root object for key '%s': %w", keyStr, err)
+		}
+		parsed.Points[i] = ParsedShare{
+			X:        points[i].X.String(),
+			Base:     rootVal.Base,
+			Encoding: rootVal.Encoding,
+			Weight:   rootVal.Weight,
+			DecodedY: points[i].Y.String(),
+			Width:    points[i].Width,
+		}
+	}
+	return parsed, nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// envShare is the JSON form of a share held in an environment variable: a
+// root object with an optional "x". Without one, the part of the variable
+// name after the prefix is used, so SHARE_3 holds the share at x=3.
+type envShare struct {
+	X string `json:"x"`
+	RootValue
+}
+
+// loadEnvShares collects every variable in environ (as returned by
+// os.Environ) whose name starts with prefix and decodes it as a share. A
+// value is either share JSON or the compact form "x:base:value". At least k
+// shares must be present; n is the number found.
+func loadEnvShares(prefix string, environ []string, k int) (KeyInfo, []Point, error) {
+	if k < 1 {
+		return KeyInfo{}, nil, fmt.Errorf("--k must be at least 1 when reading shares from the environment, got %d", k)
+	}
+
+	var points []Point
+	seen := make(map[string]string)
+	for _, kv := range environ {
+		name, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		var xStr string
+		var rootVal RootValue
+		if strings.HasPrefix(strings.TrimSpace(value), "{") {
+			var share envShare
+			if err := json.Unmarshal([]byte(value), &share); err != nil {
+				return KeyInfo{}, nil, fmt.Errorf("failed to parse share JSON in %s: %w", name, err)
+			}
+			xStr, rootVal = share.X, share.RootValue
+			if xStr == "" {
+				xStr = strings.TrimPrefix(name, prefix)
+			}
+		} else {
+			parts := strings.SplitN(value, ":", 3)
+			if len(parts) != 3 {
+				return KeyInfo{}, nil, fmt.Errorf("share in %s must be JSON or x:base:value, got %q", name, value)
+			}
+			xStr, rootVal = parts[0], RootValue{Base: parts[1], Value: parts[2]}
+		}
+
+		x, ok := new(big.Int).SetString(xStr, 10)
+		if !ok {
+			return KeyInfo{}, nil, fmt.Errorf("failed to parse x-coordinate '%s' in %s to integer", xStr, name)
+		}
+		if err := checkBits("x-coordinate", xStr, x); err != nil {
+			return KeyInfo{}, nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if err := checkDuplicateX(seen, name, x); err != nil {
+			return KeyInfo{}, nil, err
+		}
+		weight, err := shareWeight(xStr, rootVal)
+		if err != nil {
+			return KeyInfo{}, nil, fmt.Errorf("%s: %w", name, err)
+		}
+		y, err := decodeY(xStr, rootVal)
+		if err != nil {
+			return KeyInfo{}, nil, fmt.Errorf("%s: %w", name, err)
+		}
+		points = append(points, Point{X: x, Y: y, Weight: weight})
+	}
+
+	if len(points) < k {
+		return KeyInfo{}, nil, fmt.Errorf("not enough shares in environment variables %s*: need %d, got %d", prefix, k, len(points))
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].X.Cmp(points[j].X) < 0 })
+
+	return KeyInfo{N: len(points), K: k}, points, nil
+}
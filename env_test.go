@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestLoadEnvShares(t *testing.T) {
+	// f(x) = 3 + 2x, in both the JSON and the compact x:base:value form.
+	environ := []string{
+		"HOME=/root",
+		`SHARE_1={"base": "10", "value": "5"}`,
+		`SHARE_B={"x": "2", "base": "2", "value": "111"}`,
+		"SHARE_C=3:16:9",
+	}
+	keys, points, err := loadEnvShares("SHARE_", environ, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys.N != 3 || keys.K != 2 {
+		t.Errorf("keys = %+v, want n=3 k=2", keys)
+	}
+	secret, _, err := SolveWithTrace(points, keys.K)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Int64() != 3 {
+		t.Fatalf("secret = %s, want 3", secret.String())
+	}
+
+	if _, _, err := loadEnvShares("SHARE_", environ[:2], 2); err == nil {
+		t.Error("loadEnvShares accepted fewer than k shares")
+	}
+}
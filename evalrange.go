@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"text/tabwriter"
+)
+
+// maxEvalRange caps how many x-values --eval-range will evaluate.
+const maxEvalRange = 10000
+
+// parseEvalRange parses an inclusive "a:b" range of integers.
+func parseEvalRange(s string) (*big.Int, *big.Int, error) {
+	lo, hi, ok := strings.Cut(s, ":")
+	if !ok {
+		return nil, nil, fmt.Errorf("range %q must have the form a:b", s)
+	}
+	a, ok := new(big.Int).SetString(strings.TrimSpace(lo), 10)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid range start %q", lo)
+	}
+	b, ok := new(big.Int).SetString(strings.TrimSpace(hi), 10)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid range end %q", hi)
+	}
+	if a.Cmp(b) > 0 {
+		return nil, nil, fmt.Errorf("range start %s is after its end %s", a.String(), b.String())
+	}
+	if size := new(big.Int).Sub(b, a); size.Cmp(big.NewInt(maxEvalRange-1)) > 0 {
+		return nil, nil, fmt.Errorf("range %s covers more than %d values", s, maxEvalRange)
+	}
+	return a, b, nil
+}
+
+// evalRange evaluates poly at every integer x in [a, b].
+func evalRange(poly *Polynomial, a, b *big.Int) []Point {
+	var out []Point
+	for x := new(big.Int).Set(a); x.Cmp(b) <= 0; x.Add(x, big.NewInt(1)) {
+		xv := new(big.Int).Set(x)
+		out = append(out, Point{X: xv, Y: poly.Evaluate(xv)})
+	}
+	return out
+}
+
+// printEvaluations writes an x / f(x) table to out.
+func printEvaluations(out io.Writer, points []Point) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "x\tf(x)\t")
+	for _, p := range points {
+		fmt.Fprintf(w, "%s\t%s\t\n", p.X.String(), p.Y.String())
+	}
+	return w.Flush()
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Expectation is the outcome of comparing a result against its sidecar
+// .expected file.
+type Expectation struct {
+	Want string
+	OK   bool
+}
+
+// status is "OK" or "MISMATCH".
+func (e Expectation) status() string {
+	if e.OK {
+		return "OK"
+	}
+	return "MISMATCH"
+}
+
+// expectedPath is the sidecar file for file in dir: testcase1.json maps to
+// dir/testcase1.expected.
+func expectedPath(dir, file string) string {
+	base := filepath.Base(file)
+	return filepath.Join(dir, strings.TrimSuffix(base, filepath.Ext(base))+".expected")
+}
+
+// checkExpected compares r against the decimal secret in its sidecar file
+// under dir and records the outcome in r.Expected. A missing sidecar is not
+// an error; the comparison is skipped with a note on stderr.
+func checkExpected(dir string, r *Result) error {
+	path := expectedPath(dir, r.File)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		fmt.Fprintf(os.Stderr, "Note: no %s, skipping expected-value check for %s\n", path, r.File)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read expected value: %v", err)
+	}
+
+	want := strings.TrimSpace(string(data))
+	e := Expectation{Want: want}
+	if r.Secret != nil {
+		n, ok := new(big.Int).SetString(want, 10)
+		if !ok {
+			return fmt.Errorf("invalid expected value '%s' in %s: must be a decimal integer", want, path)
+		}
+		e.OK = n.Cmp(r.Secret) == 0
+	} else {
+		e.OK = want == r.unpaddedSecret()
+	}
+	r.Expected = &e
+	return nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckExpected(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "case.expected"), []byte("79836264049851\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	secret, _ := new(big.Int).SetString("79836264049851", 10)
+
+	match := Result{File: "inputs/case.json", Secret: secret}
+	if err := checkExpected(dir, &match); err != nil {
+		t.Fatal(err)
+	}
+	if match.Expected == nil || !match.Expected.OK {
+		t.Errorf("matching secret: Expected = %+v, want OK", match.Expected)
+	}
+
+	mismatch := Result{File: "case.json", Secret: big.NewInt(3)}
+	if err := checkExpected(dir, &mismatch); err != nil {
+		t.Fatal(err)
+	}
+	if mismatch.Expected == nil || mismatch.Expected.OK || mismatch.Expected.status() != "MISMATCH" {
+		t.Errorf("wrong secret: Expected = %+v, want a mismatch", mismatch.Expected)
+	}
+
+	missing := Result{File: "other.json", Secret: big.NewInt(3)}
+	if err := checkExpected(dir, &missing); err != nil || missing.Expected != nil {
+		t.Errorf("missing sidecar: err = %v, Expected = %+v, want both nil", err, missing.Expected)
+	}
+}
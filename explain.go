@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"math/big"
+)
+
+// Explanation is the machine-readable derivation of one file's secret: each
+// Lagrange term in order, followed by the final sum.
+type Explanation struct {
+	File  string
+	K     int
+	Terms []LagrangeTerm
+}
+
+type termJSON struct {
+	X           string `json:"x"`
+	Y           string `json:"y"`
+	Numerator   string `json:"numerator"`
+	Denominator string `json:"denominator"`
+	Basis       string `json:"basis"`
+	PartialSum  string `json:"partial_sum"`
+}
+
+type explanationJSON struct {
+	File   string     `json:"file"`
+	K      int        `json:"k"`
+	Terms  []termJSON `json:"terms"`
+	Secret string     `json:"secret"`
+}
+
+// MarshalJSON encodes the explanation with every big number as a string and
+// L_j(0) as an "a/b" fraction. The secret field is the last partial sum.
+func (e Explanation) MarshalJSON() ([]byte, error) {
+	out := explanationJSON{File: e.File, K: e.K, Terms: make([]termJSON, len(e.Terms))}
+	for i, t := range e.Terms {
+		out.Terms[i] = termJSON{
+			X:           t.X.String(),
+			Y:           t.Y.String(),
+			Numerator:   t.Numerator.String(),
+			Denominator: t.Denominator.String(),
+			Basis:       t.Basis.RatString(),
+			PartialSum:  t.Sum.RatString(),
+		}
+	}
+	if len(e.Terms) > 0 {
+		out.Secret = e.Terms[len(e.Terms)-1].Sum.RatString()
+	}
+	return json.Marshal(out)
+}
+
+// explainSecret reconstructs the secret from the first k points and returns
+// the derivation it was computed from.
+func explainSecret(file string, points []Point, k int) (*big.Int, *Explanation, error) {
+	secret, terms, err := solveTerms(points, k)
+	if err != nil {
+		return nil, nil, err
+	}
+	return secret, &Explanation{File: file, K: k, Terms: terms}, nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// primeChecks caches ProbablyPrime results by modulus for the lifetime of
+// the process, so a batch sharing one large prime only tests it once.
+var primeChecks = struct {
+	sync.Mutex
+	m map[string]bool
+}{m: make(map[string]bool)}
+
+// isProbablyPrime reports whether p is prime with overwhelming probability,
+// reusing an earlier answer for the same modulus.
+func isProbablyPrime(p *big.Int) bool {
+	key := p.String()
+	primeChecks.Lock()
+	defer primeChecks.Unlock()
+	prime, ok := primeChecks.m[key]
+	if !ok {
+		prime = p.ProbablyPrime(20)
+		primeChecks.m[key] = prime
+	}
+	return prime
+}
+
+// SolveMod reconstructs the secret from the first k points over the prime
+// field GF(prime), i.e. it returns f(0) mod prime.
+func SolveMod(points []Point, k int, prime *big.Int) (*big.Int, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("k must be at least 1, got %d", k)
+	}
+	if len(points) < k {
+		return nil, fmt.Errorf("not enough points provided: need %d, got %d", k, len(points))
+	}
+	points = points[:k]
+
+	secret := new(big.Int)
+	for j := range points {
+		xj := points[j].X
+
+		// L_j(0) = Π x_i / (x_i - x_j), computed modulo prime
+		numerator := big.NewInt(1)
+		denominator := big.NewInt(1)
+		for i := range points {
+			if i == j {
+				continue
+			}
+			xi := points[i].X
+			numerator.Mul(numerator, xi).Mod(numerator, prime)
+			diff := new(big.Int).Sub(xi, xj)
+			denominator.Mul(denominator, diff).Mod(denominator, prime)
+		}
+
+		inv := new(big.Int).ModInverse(denominator, prime)
+		if inv == nil {
+			return nil, fmt.Errorf("Lagrange denominator for x=%s is not invertible modulo %s (duplicate x-coordinate or non-prime modulus)", xj.String(), prime.String())
+		}
+
+		term := new(big.Int).Mul(points[j].Y, numerator)
+		term.Mul(term, inv)
+		secret.Add(secret, term).Mod(secret, prime)
+	}
+	return secret, nil
+}
+
+// SolveModWithResiduals is SolveWithResiduals over GF(prime): the secret is
+// f(0) mod prime and each residual is y_i - f(x_i) reduced into
+// 0..prime-1.
+func SolveModWithResiduals(points []Point, k int, prime *big.Int) (*big.Int, []*big.Int, error) {
+	secret, err := SolveMod(points, k, prime)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	residuals := make([]*big.Int, len(points))
+	for i, p := range points {
+		fx, err := lagrangeEvalMod(points[:k], p.X, prime)
+		if err != nil {
+			return nil, nil, err
+		}
+		r := new(big.Int).Sub(p.Y, fx)
+		residuals[i] = r.Mod(r, prime)
+	}
+	return secret, residuals, nil
+}
+
+// lagrangeEvalMod returns the value at x of the polynomial through all of
+// points over GF(prime).
+func lagrangeEvalMod(points []Point, x, prime *big.Int) (*big.Int, error) {
+	sum := new(big.Int)
+	for j := range points {
+		numerator := new(big.Int).Set(points[j].Y)
+		denominator := big.NewInt(1)
+		for i := range points {
+			if i == j {
+				continue
+			}
+			numerator.Mul(numerator, new(big.Int).Sub(x, points[i].X)).Mod(numerator, prime)
+			diff := new(big.Int).Sub(points[j].X, points[i].X)
+			denominator.Mul(denominator, diff).Mod(denominator, prime)
+		}
+
+		inv := new(big.Int).ModInverse(denominator, prime)
+		if inv == nil {
+			return nil, fmt.Errorf("Lagrange denominator for x=%s is not invertible modulo %s (duplicate x-coordinate or non-prime modulus)", points[j].X.String(), prime.String())
+		}
+		sum.Add(sum, numerator.Mul(numerator, inv)).Mod(sum, prime)
+	}
+	return sum, nil
+}
+
+// checkFieldSize reports an error when GF(prime) has too few elements to
+// hold n distinct non-zero x-coordinates, or when any x-coordinate in points
+// falls outside 1..prime-1.
+func checkFieldSize(prime *big.Int, n int, points []Point) error {
+	if prime.Cmp(big.NewInt(1)) <= 0 {
+		return fmt.Errorf("field modulus must be greater than 1, got %s", prime.String())
+	}
+	if prime.Cmp(big.NewInt(int64(n))) <= 0 {
+		return fmt.Errorf("field too small: GF(%s) has only %s non-zero elements, not enough for n=%d distinct shares", prime.String(), new(big.Int).Sub(prime, big.NewInt(1)).String(), n)
+	}
+	for _, p := range points {
+		if p.X.Sign() <= 0 || p.X.Cmp(prime) >= 0 {
+			return fmt.Errorf("field too small: x-coordinate %s is outside 1..%s of GF(%s)", p.X.String(), new(big.Int).Sub(prime, big.NewInt(1)).String(), prime.String())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestFieldTooSmall(t *testing.T) {
+	points := polyPoints([]int64{3, 2}, 1, 2, 3, 4, 5, 6, 7)
+	err := checkFieldSize(big.NewInt(5), 7, points)
+	if err == nil || !strings.Contains(err.Error(), "field too small") || !strings.Contains(err.Error(), "n=7") {
+		t.Fatalf("checkFieldSize(5, n=7) = %v, want a field-too-small error", err)
+	}
+
+	// Four shares fit GF(5), and the secret is f(0) mod 5.
+	if err := checkFieldSize(big.NewInt(5), 4, points[:4]); err != nil {
+		t.Fatalf("checkFieldSize(5, n=4): %v", err)
+	}
+	secret, err := SolveMod(points[:4], 2, big.NewInt(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Int64() != 3 {
+		t.Fatalf("SolveMod over GF(5) = %s, want 3", secret.String())
+	}
+}
+
+func TestSolveModWithResiduals(t *testing.T) {
+	prime := big.NewInt(101)
+	points := SharesFromCoefficients([]*big.Int{big.NewInt(3), big.NewInt(2), big.NewInt(1)}, 5, prime)
+	points[3].Y = new(big.Int).Mod(new(big.Int).Add(points[3].Y, big.NewInt(100)), prime)
+
+	secret, residuals, err := SolveModWithResiduals(points, 3, prime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Int64() != 3 {
+		t.Errorf("secret = %s, want 3", secret.String())
+	}
+	for i, r := range residuals {
+		want := int64(0)
+		if i == 3 {
+			want = 100
+		}
+		if r.Int64() != want {
+			t.Errorf("residual[%d] = %s, want %d", i, r.String(), want)
+		}
+	}
+}
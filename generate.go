@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SharesFromCoefficients evaluates the polynomial with the given
+// coefficients (constant term first) at x = 1..n. When prime is non-nil the
+// y-values are reduced modulo prime.
+func SharesFromCoefficients(coeffs []*big.Int, n int, prime *big.Int) []Point {
+	points := make([]Point, 0, n)
+	for i := 1; i <= n; i++ {
+		x := big.NewInt(int64(i))
+		points = append(points, Point{X: x, Y: evalCoefficients(coeffs, x, prime)})
+	}
+	return points
+}
+
+// evalCoefficients evaluates the polynomial at x using Horner's rule,
+// reducing modulo prime after each step when prime is non-nil.
+func evalCoefficients(coeffs []*big.Int, x, prime *big.Int) *big.Int {
+	y := new(big.Int)
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		y.Mul(y, x)
+		y.Add(y, coeffs[i])
+		if prime != nil {
+			y.Mod(y, prime)
+		}
+	}
+	return y
+}
+
+// ValidateShareSet checks, on the dealer side, that every share lies on the
+// polynomial with the given coefficients (constant term first), modulo
+// prime when it is non-nil. It returns the indices of the shares that do
+// not, in input order; an empty result means the whole set is good.
+func ValidateShareSet(points []Point, coeffs []*big.Int, prime *big.Int) ([]int, error) {
+	if len(coeffs) == 0 {
+		return nil, fmt.Errorf("no coefficients given")
+	}
+	if prime != nil && prime.Sign() <= 0 {
+		return nil, fmt.Errorf("modulus must be positive, got %s", prime.String())
+	}
+
+	bad := []int{}
+	for i, p := range points {
+		y := p.Y
+		if prime != nil {
+			y = new(big.Int).Mod(y, prime)
+		}
+		if evalCoefficients(coeffs, p.X, prime).Cmp(y) != 0 {
+			bad = append(bad, i)
+		}
+	}
+	return bad, nil
+}
+
+// writeTestCase writes keys and points to w in the test case JSON format,
+// with every y-value in base 10 and the shares in x order.
+func writeTestCase(w io.Writer, keys KeyInfo, points []Point) error {
+	keysJSON, err := json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "{\n  \"keys\": %s", keysJSON)
+	for _, p := range points {
+		root, err := json.Marshal(RootValue{Base: "10", Value: p.Y.String()})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, ",\n  %q: %s", p.X.String(), root)
+	}
+	b.WriteString("\n}\n")
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// writeSplitShares writes each point to dir/share_X.json, where X is its
+// x-coordinate, as a test case holding the shared keys and that one share,
+// so that every shareholder can be handed a separate file. Any k of the
+// files can be reconstructed together with --collect.
+func writeSplitShares(dir string, keys KeyInfo, points []Point) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	for _, p := range points {
+		path := filepath.Join(dir, "share_"+p.X.String()+".json")
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", path, err)
+		}
+		if err := writeTestCase(f, keys, []Point{p}); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runGen implements the "gen" subcommand, which writes a test case whose
+// shares come from an explicit list of polynomial coefficients.
+func runGen(args []string) error {
+	fs := flag.NewFlagSet("gen", flag.ExitOnError)
+	coeffList := fs.String("coeffs", "", "comma-separated polynomial `coefficients`, constant term first")
+	n := fs.Int("n", 0, "number of shares to generate")
+	primeStr := fs.String("prime", "", "reduce the shares modulo this `prime`")
+	out := fs.String("out", "", "write the test case to this `file` instead of stdout")
+	splitDir := fs.String("split-output-dir", "", "write each share to its own share_X.json in this `dir`")
+	fs.Parse(args)
+
+	if *out != "" && *splitDir != "" {
+		return fmt.Errorf("--out and --split-output-dir cannot be combined")
+	}
+	if *coeffList == "" {
+		return fmt.Errorf("--coeffs is required")
+	}
+	var coeffs []*big.Int
+	for _, s := range strings.Split(*coeffList, ",") {
+		c, ok := new(big.Int).SetString(strings.TrimSpace(s), 10)
+		if !ok {
+			return fmt.Errorf("invalid coefficient '%s'", s)
+		}
+		coeffs = append(coeffs, c)
+	}
+	if *n < len(coeffs) {
+		return fmt.Errorf("--n must be at least the number of coefficients (%d), got %d", len(coeffs), *n)
+	}
+
+	var prime *big.Int
+	if *primeStr != "" {
+		var ok bool
+		prime, ok = new(big.Int).SetString(*primeStr, 10)
+		if !ok || prime.Sign() <= 0 {
+			return fmt.Errorf("invalid --prime '%s'", *primeStr)
+		}
+	}
+
+	points := SharesFromCoefficients(coeffs, *n, prime)
+	keys := KeyInfo{N: *n, K: len(coeffs)}
+
+	if *splitDir != "" {
+		return writeSplitShares(*splitDir, keys, points)
+	}
+	if *out == "" {
+		return writeTestCase(os.Stdout, keys, points)
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", *out, err)
+	}
+	if err := writeTestCase(f, keys, points); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", *out, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"math/big"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestValidateShareSet(t *testing.T) {
+	coeffs := []*big.Int{big.NewInt(3), big.NewInt(2), big.NewInt(1)}
+	prime := big.NewInt(101)
+	points := SharesFromCoefficients(coeffs, 6, prime)
+
+	bad, err := ValidateShareSet(points, coeffs, prime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bad) != 0 {
+		t.Fatalf("fresh shares flagged: %v", bad)
+	}
+
+	points[4].Y = new(big.Int).Add(points[4].Y, big.NewInt(1))
+	bad, err = ValidateShareSet(points, coeffs, prime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(bad, []int{4}) {
+		t.Fatalf("corrupted share flagged as %v, want [4]", bad)
+	}
+}
+
+func TestSplitSharesCollect(t *testing.T) {
+	dir := t.TempDir()
+	coeffs := []*big.Int{big.NewInt(42), big.NewInt(5), big.NewInt(7)}
+	if err := writeSplitShares(dir, KeyInfo{N: 5, K: 3}, SharesFromCoefficients(coeffs, 5, nil)); err != nil {
+		t.Fatal(err)
+	}
+	files := []string{
+		filepath.Join(dir, "share_5.json"),
+		filepath.Join(dir, "share_2.json"),
+		filepath.Join(dir, "share_4.json"),
+	}
+	keys, points, err := loadShareFiles(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, _, err := SolveWithTrace(points, keys.K)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Int64() != 42 {
+		t.Fatalf("collected secret = %s, want 42", secret.String())
+	}
+
+	if _, _, err := loadShareFiles(files[:2]); err == nil {
+		t.Error("loadShareFiles accepted fewer than k shares")
+	}
+	conflict := writeFile(t, "share_2.json", `{"keys": {"n": 5, "k": 3}, "2": {"base": "10", "value": "1"}}`)
+	if _, _, err := loadShareFiles(append(files, conflict)); err == nil {
+		t.Error("loadShareFiles accepted two different shares at x=2")
+	}
+}
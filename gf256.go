@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// GF(2^8) arithmetic as used by byte-oriented Shamir tools: elements are
+// bytes, addition is XOR, and multiplication is polynomial multiplication
+// reduced by the AES polynomial x^8 + x^4 + x^3 + x + 1 (0x11b).
+
+// gfExp and gfLog are the exponential and logarithm tables for the
+// generator 3. gfExp is doubled in length so gfMul can skip a reduction
+// modulo 255.
+var gfExp, gfLog = gfTables()
+
+func gfTables() (exp [510]byte, log [256]byte) {
+	x := 1
+	for i := 0; i < 255; i++ {
+		exp[i] = byte(x)
+		exp[i+255] = byte(x)
+		log[x] = byte(i)
+		// Multiply by the generator 3 = x + 1: x*3 = (x << 1) ^ x.
+		x ^= x << 1
+		if x&0x100 != 0 {
+			x ^= 0x11b
+		}
+	}
+	return exp, log
+}
+
+// gfMul multiplies two elements of GF(2^8).
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+// gfDiv divides a by the non-zero element b in GF(2^8).
+func gfDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+255-int(gfLog[b])]
+}
+
+// gfInterpolateAtZero returns f(0) for the polynomial over GF(2^8) through
+// the points (xs[i], ys[i]). The xs must be distinct and non-zero.
+func gfInterpolateAtZero(xs, ys []byte) byte {
+	var secret byte
+	for j := range xs {
+		// L_j(0) = Π x_i / (x_i - x_j); subtraction is XOR.
+		basis := byte(1)
+		for i := range xs {
+			if i == j {
+				continue
+			}
+			basis = gfMul(basis, gfDiv(xs[i], xs[i]^xs[j]))
+		}
+		secret ^= gfMul(ys[j], basis)
+	}
+	return secret
+}
+
+// SolveGF256 reconstructs a byte-string secret from the first k points over
+// GF(2^8). Each y-value is read as big-endian bytes, left-padded with zeros
+// to the longest share, and every byte position is interpolated on its own.
+// The x-coordinates must be distinct values in 1..255.
+func SolveGF256(points []Point, k int) ([]byte, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("k must be at least 1, got %d", k)
+	}
+	if len(points) < k {
+		return nil, fmt.Errorf("not enough points provided: need %d, got %d", k, len(points))
+	}
+	points = points[:k]
+
+	xs := make([]byte, k)
+	seen := make(map[byte]bool, k)
+	width := 0
+	for i, p := range points {
+		if p.X.Sign() <= 0 || p.X.Cmp(big.NewInt(255)) > 0 {
+			return nil, fmt.Errorf("x-coordinate %s is outside 1..255 of GF(256)", p.X.String())
+		}
+		if p.Y.Sign() < 0 {
+			return nil, fmt.Errorf("y-value %s at x=%s is negative", p.Y.String(), p.X.String())
+		}
+		xs[i] = byte(p.X.Int64())
+		if seen[xs[i]] {
+			return nil, fmt.Errorf("duplicate x-coordinate %d", xs[i])
+		}
+		seen[xs[i]] = true
+		width = max(width, len(p.Y.Bytes()))
+	}
+
+	rows := make([][]byte, k)
+	for i, p := range points {
+		rows[i] = p.Y.FillBytes(make([]byte, width))
+	}
+	return solveGF256Bytes(xs, rows), nil
+}
+
+// solveGF256Bytes interpolates each byte position of the equal-length rows
+// independently, where rows[i] belongs to the share at xs[i].
+func solveGF256Bytes(xs []byte, rows [][]byte) []byte {
+	width := len(rows[0])
+	secret := make([]byte, width)
+	ys := make([]byte, len(xs))
+	for b := 0; b < width; b++ {
+		for i := range rows {
+			ys[i] = rows[i][b]
+		}
+		secret[b] = gfInterpolateAtZero(xs, ys)
+	}
+	return secret
+}
+
+// ByteShare is one share of a byte-wise GF(2^8) sharing: every byte of the
+// secret is shared independently, all at the same x.
+type ByteShare struct {
+	X byte
+	Y []byte
+}
+
+// SolveGF256Shares reconstructs a byte-string secret from the first k
+// shares. Every share, not only those first k, must be the same length,
+// since a share of another length cannot belong to the same secret; the
+// first k must also have distinct non-zero x.
+func SolveGF256Shares(shares []ByteShare, k int) ([]byte, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("k must be at least 1, got %d", k)
+	}
+	if len(shares) < k {
+		return nil, fmt.Errorf("not enough points provided: need %d, got %d", k, len(shares))
+	}
+	for _, s := range shares {
+		if len(s.Y) != len(shares[0].Y) {
+			return nil, fmt.Errorf("share at x=%d is %d bytes but share at x=%d is %d bytes", s.X, len(s.Y), shares[0].X, len(shares[0].Y))
+		}
+	}
+	shares = shares[:k]
+
+	xs := make([]byte, k)
+	rows := make([][]byte, k)
+	seen := make(map[byte]bool, k)
+	for i, s := range shares {
+		if s.X == 0 {
+			return nil, fmt.Errorf("x-coordinate 0 is not allowed in GF(256): it would reveal the secret")
+		}
+		if seen[s.X] {
+			return nil, fmt.Errorf("duplicate x-coordinate %d", s.X)
+		}
+		seen[s.X] = true
+		xs[i], rows[i] = s.X, s.Y
+	}
+	return solveGF256Bytes(xs, rows), nil
+}
+
+// SplitGF256 shares every byte of secret with a random polynomial of degree
+// k-1 over GF(2^8) and returns n shares at x = 1..n. Coefficients are read
+// from random, normally crypto/rand.Reader.
+func SplitGF256(secret []byte, n, k int, random io.Reader) ([]ByteShare, error) {
+	if k < 1 || k > n {
+		return nil, fmt.Errorf("need 1 <= k <= n, got k=%d n=%d", k, n)
+	}
+	if n > 255 {
+		return nil, fmt.Errorf("GF(256) allows at most 255 shares, got n=%d", n)
+	}
+
+	coeffs := make([]byte, len(secret)*(k-1))
+	if _, err := io.ReadFull(random, coeffs); err != nil {
+		return nil, fmt.Errorf("failed to read random coefficients: %w", err)
+	}
+
+	shares := make([]ByteShare, n)
+	for i := range shares {
+		x := byte(i + 1)
+		y := make([]byte, len(secret))
+		for b, s := range secret {
+			// Horner's rule from the highest coefficient down to the secret.
+			var acc byte
+			for c := k - 2; c >= 0; c-- {
+				acc = gfMul(acc, x) ^ coeffs[b*(k-1)+c]
+			}
+			y[b] = gfMul(acc, x) ^ s
+		}
+		shares[i] = ByteShare{X: x, Y: y}
+	}
+	return shares, nil
+}
+
+// loadGF256TestCase reads a test case whose shares are byte strings
+// (hexbytes or base64 encoded) for byte-wise GF(2^8) reconstruction, and
+// the hex SHA-256 of the file.
+func loadGF256TestCase(filePath string) (KeyInfo, []ByteShare, string, error) {
+	jsonData, err := readTestFile(filePath)
+	if err != nil {
+		return KeyInfo{}, nil, "", err
+	}
+	keys, rawData, orderedKeys, err := parseTestCase(jsonData, filePath)
+	if err != nil {
+		return KeyInfo{}, nil, "", err
+	}
+
+	var shares []ByteShare
+	for _, keyStr := range orderedKeys {
+		x, ok := new(big.Int).SetString(keyStr, 10)
+		if !ok || x.Sign() <= 0 || x.Cmp(big.NewInt(255)) > 0 {
+			return KeyInfo{}, nil, "", fmt.Errorf("x-coordinate '%s' must be an integer in 1..255 for GF(256)", keyStr)
+		}
+		var rootVal RootValue
+		if err := json.Unmarshal(rawData[keyStr], &rootVal); err != nil {
+			return KeyInfo{}, nil, "", fmt.Errorf("failed to parse root object for key '%s' (got %s): %w", keyStr, snippet(rawData[keyStr]), err)
+		}
+		y, err := shareBytes(keyStr, rootVal)
+		if err != nil {
+			return KeyInfo{}, nil, "", err
+		}
+		shares = append(shares, ByteShare{X: byte(x.Int64()), Y: y})
+	}
+
+	if len(shares) < keys.K {
+		return KeyInfo{}, nil, "", fmt.Errorf("not enough points provided: need %d, got %d", keys.K, len(shares))
+	}
+	return keys, shares, hashBytes(jsonData), nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestGF256RoundTrip(t *testing.T) {
+	for secret := 0; secret < 256; secret += 17 {
+		shares, err := SplitGF256([]byte{byte(secret)}, 5, 3, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Any three of the five shares will do; take the last three.
+		var points []Point
+		for _, s := range shares[2:] {
+			points = append(points, Point{X: big.NewInt(int64(s.X)), Y: new(big.Int).SetBytes(s.Y)})
+		}
+		got, err := SolveGF256(points, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// A share whose byte is zero decodes as an empty y, so compare values.
+		if new(big.Int).SetBytes(got).Int64() != int64(secret) {
+			t.Errorf("secret %d reconstructed as %x", secret, got)
+		}
+	}
+}
+
+func TestGF256SharesRoundTrip(t *testing.T) {
+	secret := []byte("\x00\x00multi-byte secret\xff")
+	shares, err := SplitGF256(secret, 6, 4, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, subset := range [][]int{{0, 1, 2, 3}, {5, 3, 1, 0}, {2, 3, 4, 5}} {
+		chosen := make([]ByteShare, len(subset))
+		for i, j := range subset {
+			chosen[i] = shares[j]
+		}
+		got, err := SolveGF256Shares(chosen, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, secret) {
+			t.Errorf("shares %v reconstruct %q, want %q", subset, got, secret)
+		}
+	}
+
+	short := []ByteShare{shares[0], {X: shares[1].X, Y: shares[1].Y[1:]}, shares[2], shares[3]}
+	if _, err := SolveGF256Shares(short, 4); err == nil {
+		t.Error("SolveGF256Shares accepted shares of unequal length")
+	}
+	// A short share after the first k must be caught too.
+	extra := []ByteShare{shares[0], shares[1], shares[2], shares[3], {X: shares[4].X, Y: shares[4].Y[1:]}}
+	if _, err := SolveGF256Shares(extra, 4); err == nil {
+		t.Error("SolveGF256Shares accepted a short share beyond the first k")
+	}
+}
+
+func TestGF256FileUnequalLength(t *testing.T) {
+	file := writeFile(t, "bytes.json", `{
+		"keys": {"n": 3, "k": 2},
+		"1": {"encoding": "hexbytes", "value": "0102"},
+		"2": {"encoding": "hexbytes", "value": "0203"},
+		"3": {"encoding": "hexbytes", "value": "aa"}
+	}`)
+	_, err := solveFile(file, &options{load: loadTestCaseDigest, gf256: true})
+	if err == nil || !strings.Contains(err.Error(), "1 bytes") {
+		t.Fatalf("solveFile = %v, want an unequal length error for the share at x=3", err)
+	}
+}
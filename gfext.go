@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+	"strconv"
+)
+
+// maxGFExtDegree is the largest m SolveGFExt supports, so that elements and
+// their unreduced products fit in a uint64.
+const maxGFExtDegree = 32
+
+// gfExtMul multiplies a and b in GF(2^m) defined by the irreducible
+// polynomial modPoly (a bitmask with bit m set): a carryless multiply,
+// reducing by modPoly whenever the running multiple of a reaches degree m.
+func gfExtMul(a, b, modPoly uint64, m int) uint64 {
+	var product uint64
+	for b != 0 {
+		if b&1 != 0 {
+			product ^= a
+		}
+		b >>= 1
+		a <<= 1
+		if a&(1<<m) != 0 {
+			a ^= modPoly
+		}
+	}
+	return product
+}
+
+// gfExtInv returns the multiplicative inverse of a in GF(2^m) as
+// a^(2^m - 2), or false if a has none, which for non-zero a means modPoly
+// is not irreducible.
+func gfExtInv(a, modPoly uint64, m int) (uint64, bool) {
+	if a == 0 {
+		return 0, false
+	}
+	inv, base := uint64(1), a
+	for e := uint64(1)<<m - 2; e != 0; e >>= 1 {
+		if e&1 != 0 {
+			inv = gfExtMul(inv, base, modPoly, m)
+		}
+		base = gfExtMul(base, base, modPoly, m)
+	}
+	return inv, gfExtMul(inv, a, modPoly, m) == 1
+}
+
+// SolveGFExt reconstructs the secret from the first k points over GF(2^m),
+// the field defined by the degree-m irreducible polynomial modPoly given as
+// an integer bitmask; x^4 + x + 1, for instance, is 0x13 with m = 4. Every
+// coordinate must be an element of the field, i.e. below 2^m.
+func SolveGFExt(points []Point, k int, modPoly uint64, m int) (*big.Int, error) {
+	if m < 1 || m > maxGFExtDegree {
+		return nil, fmt.Errorf("field degree m must be between 1 and %d, got %d", maxGFExtDegree, m)
+	}
+	if bits.Len64(modPoly) != m+1 {
+		return nil, fmt.Errorf("modulus polynomial %#x does not have degree %d", modPoly, m)
+	}
+	if k < 1 {
+		return nil, fmt.Errorf("k must be at least 1, got %d", k)
+	}
+	if len(points) < k {
+		return nil, fmt.Errorf("not enough points provided: need %d, got %d", k, len(points))
+	}
+	points = points[:k]
+
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(m))
+	xs := make([]uint64, k)
+	ys := make([]uint64, k)
+	for i, p := range points {
+		if p.X.Sign() <= 0 || p.X.Cmp(limit) >= 0 {
+			return nil, fmt.Errorf("x-coordinate %s is not a non-zero element of GF(2^%d)", p.X.String(), m)
+		}
+		if p.Y.Sign() < 0 || p.Y.Cmp(limit) >= 0 {
+			return nil, fmt.Errorf("y-value %s at x=%s is not an element of GF(2^%d)", p.Y.String(), p.X.String(), m)
+		}
+		xs[i], ys[i] = p.X.Uint64(), p.Y.Uint64()
+	}
+
+	var secret uint64
+	for j := range xs {
+		// L_j(0) = Π x_i / (x_i - x_j); subtraction is XOR.
+		basis := uint64(1)
+		for i := range xs {
+			if i == j {
+				continue
+			}
+			inv, ok := gfExtInv(xs[i]^xs[j], modPoly, m)
+			if !ok {
+				if xs[i] == xs[j] {
+					return nil, fmt.Errorf("duplicate x-coordinate %d", xs[i])
+				}
+				return nil, fmt.Errorf("%d has no inverse modulo %#x: the polynomial is not irreducible", xs[i]^xs[j], modPoly)
+			}
+			basis = gfExtMul(basis, gfExtMul(xs[i], inv, modPoly, m), modPoly, m)
+		}
+		secret ^= gfExtMul(ys[j], basis, modPoly, m)
+	}
+	return new(big.Int).SetUint64(secret), nil
+}
+
+// parseGFPoly parses a --gf2m-poly bitmask, such as 0x13, and returns it
+// with the degree m of the field it defines.
+func parseGFPoly(s string) (uint64, int, error) {
+	poly, err := strconv.ParseUint(s, 0, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid polynomial %q: %v", s, err)
+	}
+	m := bits.Len64(poly) - 1
+	if m < 1 || m > maxGFExtDegree {
+		return 0, 0, fmt.Errorf("polynomial %q has degree %d, want 1 to %d", s, m, maxGFExtDegree)
+	}
+	return poly, m, nil
+}
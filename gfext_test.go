@@ -0,0 +1,35 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestGFExtRoundTrip(t *testing.T) {
+	const modPoly, m = 0x13, 4 // x^4 + x + 1
+	coeffs := []uint64{0x9, 0x5, 0xe}
+	eval := func(x uint64) uint64 {
+		var y uint64
+		for i := len(coeffs) - 1; i >= 0; i-- {
+			y = gfExtMul(y, x, modPoly, m) ^ coeffs[i]
+		}
+		return y
+	}
+	var points []Point
+	for x := uint64(1); x < 16; x++ {
+		points = append(points, Point{X: new(big.Int).SetUint64(x), Y: new(big.Int).SetUint64(eval(x))})
+	}
+	for start := 0; start+3 <= len(points); start += 4 {
+		secret, err := SolveGFExt(points[start:], 3, modPoly, m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if secret.Uint64() != coeffs[0] {
+			t.Errorf("shares from x=%d: secret %s, want %d", start+1, secret.String(), coeffs[0])
+		}
+	}
+
+	if _, err := SolveGFExt(points, 3, 0x15, m); err == nil {
+		t.Error("SolveGFExt accepted the reducible x^4 + x^2 + 1")
+	}
+}
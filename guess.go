@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"text/tabwriter"
+)
+
+// BaseGuess is one base a share's value parses in, with the result.
+type BaseGuess struct {
+	Base  int
+	Value *big.Int
+}
+
+// guessBases are the bases GuessBases tries: every base big.Int knows the
+// letters of, plus the full base 62.
+var guessBases = func() []int {
+	var bases []int
+	for b := 2; b <= 36; b++ {
+		bases = append(bases, b)
+	}
+	return append(bases, 62)
+}()
+
+// GuessBases tries value in each of bases 2-36 and 62 with the current
+// alphabet and returns every base it parses in, smallest first. When a
+// share's declared base looks wrong, the plausible alternatives and what
+// they decode to are then easy to compare.
+func GuessBases(value string) []BaseGuess {
+	var guesses []BaseGuess
+	for _, base := range guessBases {
+		y, err := parseBase(value, base)
+		if err != nil {
+			continue
+		}
+		guesses = append(guesses, BaseGuess{Base: base, Value: y})
+	}
+	return guesses
+}
+
+// printBaseGuesses reads the share at key in file and writes a table of
+// every base its value parses in, marking the base the share declares.
+func printBaseGuesses(file, key string) error {
+	_, rawData, _, err := readTestCase(file)
+	if err != nil {
+		return err
+	}
+	raw, ok := rawData[key]
+	if !ok {
+		return fmt.Errorf("no share with key '%s' in %s", key, file)
+	}
+	var rootVal RootValue
+	if err := json.Unmarshal(raw, &rootVal); err != nil {
+		return fmt.Errorf("failed to parse root object for key '%s' (got %s): %w", key, snippet(raw), err)
+	}
+
+	guesses := GuessBases(rootVal.Value)
+	fmt.Printf("Share '%s' of %s: value %q, declared base %s\n", key, file, rootVal.Value, rootVal.Base)
+	if len(guesses) == 0 {
+		fmt.Println("  parses in no base from 2 to 62")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "base\tvalue\t\t")
+	for _, g := range guesses {
+		mark := ""
+		if fmt.Sprint(g.Base) == rootVal.Base {
+			mark = "declared"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t\n", g.Base, g.Value.String(), mark)
+	}
+	return w.Flush()
+}
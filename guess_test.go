@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestGuessBases(t *testing.T) {
+	// "1a" is a digit too large for bases below 11 and means 1*b + 10 in
+	// every base from 11 up.
+	guesses := GuessBases("1a")
+	if len(guesses) != 27 {
+		t.Fatalf("GuessBases(\"1a\") gave %d bases, want 27 (11-36 and 62)", len(guesses))
+	}
+	for _, g := range guesses {
+		if want := int64(g.Base + 10); g.Value.Int64() != want {
+			t.Errorf("base %d: value %s, want %d", g.Base, g.Value.String(), want)
+		}
+	}
+	if guesses[0].Base != 11 || guesses[len(guesses)-1].Base != 62 {
+		t.Errorf("bases run %d to %d, want 11 to 62", guesses[0].Base, guesses[len(guesses)-1].Base)
+	}
+
+	if guesses := GuessBases("!"); len(guesses) != 0 {
+		t.Errorf("GuessBases(\"!\") = %v, want none", guesses)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// intPathThreshold is the smallest k for which MethodAuto uses SolveInt
+// rather than the big.Rat path, set by --int-path-threshold. BenchmarkSolve,
+// on random 256-bit polynomials, puts the integer path ahead at every k it
+// measures (about 2x at k=3, 1.5x at k=50). At k=1 both paths just return
+// y, so that one case stays on the reference big.Rat path.
+var intPathThreshold = 2
+
+// SolveInt reconstructs the secret from the first k points using only
+// integer arithmetic. Each Lagrange term y_j * N_j / D_j is scaled to the
+// least common multiple L of the denominators, so f(0) = (Σ y_j*N_j*(L/D_j)) / L
+// needs a single exact division at the end instead of a big.Rat
+// normalisation after every addition.
+func SolveInt(points []Point, k int) (*big.Int, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("k must be at least 1, got %d", k)
+	}
+	if len(points) < k {
+		return nil, fmt.Errorf("not enough points provided: need %d, got %d", k, len(points))
+	}
+	points = points[:k]
+
+	numerators := make([]*big.Int, k)
+	denominators := make([]*big.Int, k)
+	lcm := big.NewInt(1)
+	gcd := new(big.Int)
+	for j := range points {
+		numerator := new(big.Int).Set(points[j].Y)
+		denominator := big.NewInt(1)
+		for i := range points {
+			if i == j {
+				continue
+			}
+			diff := new(big.Int).Sub(points[i].X, points[j].X)
+			if diff.Sign() == 0 {
+				return nil, fmt.Errorf("duplicate x-coordinate %s", points[i].X.String())
+			}
+			numerator.Mul(numerator, points[i].X)
+			denominator.Mul(denominator, diff)
+		}
+		numerators[j], denominators[j] = numerator, denominator
+
+		// lcm = lcm * |d| / gcd(lcm, |d|)
+		d := new(big.Int).Abs(denominator)
+		gcd.GCD(nil, nil, lcm, d)
+		lcm.Mul(lcm, d.Quo(d, gcd))
+	}
+
+	sum := new(big.Int)
+	for j := range points {
+		scale := new(big.Int).Quo(lcm, denominators[j])
+		sum.Add(sum, scale.Mul(scale, numerators[j]))
+	}
+
+	secret, rem := new(big.Int).QuoRem(sum, lcm, new(big.Int))
+	if rem.Sign() != 0 {
+		return nil, fmt.Errorf("fatal: final result is not an integer, something went wrong with the calculation. Result: %s", new(big.Rat).SetFrac(sum, lcm).FloatString(5))
+	}
+	return secret, nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// randomPoints returns n points at x = 1..n on a random polynomial of
+// degree k-1 with 256-bit coefficients, the same for every seed.
+func randomPoints(seed int64, k, n int) []Point {
+	rng := rand.New(rand.NewSource(seed))
+	limit := new(big.Int).Lsh(big.NewInt(1), 256)
+	coeffs := make([]*big.Int, k)
+	for i := range coeffs {
+		coeffs[i] = new(big.Int).Rand(rng, limit)
+	}
+	poly := &Polynomial{Coeffs: coeffs}
+	points := make([]Point, n)
+	for i := range points {
+		x := big.NewInt(int64(i + 1))
+		points[i] = Point{X: x, Y: poly.Evaluate(x)}
+	}
+	return points
+}
+
+func TestSolveIntMatchesRat(t *testing.T) {
+	for _, k := range []int{1, 2, 3, 10, 50} {
+		points := randomPoints(int64(k), k, k)
+		want, _, err := SolveWithTrace(points, k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := SolveInt(points, k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Cmp(want) != 0 {
+			t.Errorf("k=%d: SolveInt = %s, SolveWithTrace = %s", k, got.String(), want.String())
+		}
+	}
+}
+
+// BenchmarkSolve compares the integer and big.Rat paths that MethodAuto
+// chooses between at intPathThreshold.
+func BenchmarkSolve(b *testing.B) {
+	for _, k := range []int{1, 2, 3, 10, 50} {
+		points := randomPoints(int64(k), k, k)
+		b.Run(fmt.Sprintf("int/k=%d", k), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := SolveInt(points, k); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("rat/k=%d", k), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, _, err := SolveWithTrace(points, k); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -1,13 +1,17 @@
 package main
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"math/big"
 	"os"
 	"sort"
-	"strconv"
+	"strings"
 )
 
 // Point represents a decoded (x, y) coordinate for the polynomial.
@@ -15,6 +19,34 @@ import (
 type Point struct {
 	X *big.Int
 	Y *big.Int
+	// Weight is the share's optional "weight", how much it is trusted in
+	// weighted consensus. Zero means the share gave none (see weight).
+	Weight float64
+	// Width is the byte length of a hexbytes or base64 share, leading zero
+	// bytes included, recorded only with --preserve-width.
+	Width int
+}
+
+// weight returns the share's consensus weight, 1 unless it set one.
+func (p Point) weight() float64 {
+	if p.Weight == 0 {
+		return 1
+	}
+	return p.Weight
+}
+
+// pointJSON is the wire form of a Point, with both coordinates as decimal
+// strings so that no precision is lost.
+type pointJSON struct {
+	X     string `json:"x"`
+	Y     string `json:"y"`
+	Width int    `json:"width,omitempty"`
+}
+
+// MarshalJSON encodes the point as {"x":"...","y":"..."}, plus its byte
+// width when one was recorded.
+func (p Point) MarshalJSON() ([]byte, error) {
+	return json.Marshal(pointJSON{X: p.X.String(), Y: p.Y.String(), Width: p.Width})
 }
 
 // KeyInfo holds the metadata from the "keys" object in the JSON.
@@ -24,34 +56,184 @@ type KeyInfo struct {
 }
 
 // RootValue represents the encoded Y value and its base from the JSON.
+// Encoding selects a non-standard digit system for Value (see decoders).
+// Factors, when present, gives y as a list of [prime, exponent] pairs
+// instead of a base-N string.
 type RootValue struct {
-	Base  string `json:"base"`
-	Value string `json:"value"`
+	Base     string          `json:"base"`
+	Value    string          `json:"value"`
+	Encoding string          `json:"encoding,omitempty"`
+	Factors  [][]json.Number `json:"factors,omitempty"`
+	Weight   *float64        `json:"weight,omitempty"`
 }
 
 // solveForSecret reads a test case file, decodes the points,
 // and calculates the polynomial's constant term 'c'.
 func solveForSecret(filePath string) (*big.Int, error) {
+	keys, points, err := loadTestCase(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// --- 3. Find the Secret (C) using Lagrange Interpolation ---
+	secret, _, err := SolveWithTrace(points, keys.K)
+	if err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// loadTestCase reads a test case file and returns its 'keys' object
+// together with every decoded point, in key order. Solvers use the
+// first k of them.
+func loadTestCase(filePath string) (KeyInfo, []Point, error) {
+	keys, points, _, err := loadTestCaseDigest(filePath)
+	return keys, points, err
+}
+
+// loadTestCaseDigest is loadTestCase that also returns the hex SHA-256 of
+// the bytes the shares were decoded from.
+func loadTestCaseDigest(filePath string) (KeyInfo, []Point, string, error) {
+	jsonData, err := readTestFile(filePath)
+	if err != nil {
+		return KeyInfo{}, nil, "", err
+	}
+	keys, rawData, sortedKeys, err := parseTestCase(jsonData, filePath)
+	if err != nil {
+		return KeyInfo{}, nil, "", err
+	}
+
+	points, err := decodePoints(keys, rawData, sortedKeys)
+	if err != nil {
+		return KeyInfo{}, nil, "", err
+	}
+	if len(points) < len(sortedKeys) {
+		fmt.Fprintf(os.Stderr, "Note: stopped after decoding %d of %d shares in %s (--stop-at-k)\n", len(points), len(sortedKeys), filePath)
+	}
+	return keys, points, hashBytes(jsonData), nil
+}
+
+// decodePoints decodes the shares stored under orderedKeys into points, in
+// that order, and checks that there are at least k of them.
+func decodePoints(keys KeyInfo, rawData map[string]json.RawMessage, orderedKeys []string) ([]Point, error) {
+	// --- 2. Decode the Y Values and collect points ---
+	var points []Point
+	// Keys are distinct strings, but "3" and "03" are the same x; seen maps
+	// each numeric x back to the key that first used it.
+	seen := make(map[string]string)
+	// Only 'k' points are needed to define the polynomial, but the rest are
+	// decoded too so they can be dumped or checked against it.
+	for _, keyStr := range orderedKeys {
+		// The key is the 'x' coordinate
+		x, ok := new(big.Int).SetString(keyStr, 10)
+		if !ok {
+			return nil, fmt.Errorf("failed to parse x-coordinate '%s' to integer", keyStr)
+		}
+		if err := checkBits("x-coordinate", keyStr, x); err != nil {
+			return nil, err
+		}
+		if err := checkDuplicateX(seen, keyStr, x); err != nil {
+			return nil, err
+		}
+
+		// Decode the corresponding 'y' coordinate
+		point, err := decodeShare(keyStr, rawData[keyStr])
+		if err != nil {
+			return nil, err
+		}
+		point.X = x
+
+		points = append(points, point)
+		if stopAtK && documentOrder && len(points) == keys.K {
+			break
+		}
+	}
+
+	if len(points) < keys.K {
+		return nil, fmt.Errorf("not enough points provided: need %d, got %d", keys.K, len(points))
+	}
+
+	return points, nil
+}
+
+// checkDuplicateX records that key decoded to x in seen, and reports an
+// error if an earlier key already decoded to the same value. x is a
+// *big.Int or a *big.Rat, whose String forms are both canonical.
+func checkDuplicateX(seen map[string]string, key string, x fmt.Stringer) error {
+	norm := x.String()
+	if first, dup := seen[norm]; dup {
+		return fmt.Errorf("duplicate x-coordinate %s: keys '%s' and '%s' have the same value", norm, first, key)
+	}
+	seen[norm] = key
+	return nil
+}
+
+// hashBytes returns the hex SHA-256 of data.
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// readTestCase reads and unmarshals a test case file, returning its 'keys'
+// object, the raw share objects, and the share keys in numeric order (or
+// document order with --no-sort).
+func readTestCase(filePath string) (KeyInfo, map[string]json.RawMessage, []string, error) {
 	// --- 1. Read the Test Case (Input) from a separate JSON file ---
-	jsonData, err := os.ReadFile(filePath)
+	jsonData, err := readTestFile(filePath)
+	if err != nil {
+		return KeyInfo{}, nil, nil, err
+	}
+	return parseTestCase(jsonData, filePath)
+}
+
+// readTestFile reads the raw bytes of an input file, retrying as set by
+// --retry.
+func readTestFile(filePath string) ([]byte, error) {
+	data, err := readFileRetry(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file %s: %w", filePath, err)
 	}
+	return data, nil
+}
 
+// parseTestCase is readTestCase for JSON that is already in memory; name
+// identifies it in error messages.
+func parseTestCase(jsonData []byte, filePath string) (KeyInfo, map[string]json.RawMessage, []string, error) {
 	// Use a map to handle the dynamic keys ("1", "2", "3", etc.)
 	var rawData map[string]json.RawMessage
 	if err := json.Unmarshal(jsonData, &rawData); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal json from %s: %w", filePath, err)
+		return KeyInfo{}, nil, nil, fmt.Errorf("failed to unmarshal json from %s: %w", filePath, err)
+	}
+
+	keys, sortedKeys, err := parseRawMap(rawData, filePath)
+	if err != nil {
+		return KeyInfo{}, nil, nil, err
 	}
 
+	if documentOrder {
+		orderedKeys, err := documentKeys(jsonData)
+		if err != nil {
+			return KeyInfo{}, nil, nil, fmt.Errorf("failed to read share order from %s: %w", filePath, err)
+		}
+		return keys, rawData, orderedKeys, nil
+	}
+	return keys, rawData, sortedKeys, nil
+}
+
+// parseRawMap reads the 'keys' object out of an unmarshalled test case and
+// returns it with the share keys in numeric order. A map has no document
+// order, so --no-sort cannot apply here.
+func parseRawMap(rawData map[string]json.RawMessage, name string) (KeyInfo, []string, error) {
 	// Parse the 'keys' object
 	var keys KeyInfo
 	if err := json.Unmarshal(rawData["keys"], &keys); err != nil {
-		return nil, fmt.Errorf("failed to parse 'keys' object in %s: %w", filePath, err)
+		return KeyInfo{}, nil, fmt.Errorf("failed to parse 'keys' object in %s: %w", name, err)
+	}
+
+	if keys.K < 1 {
+		return KeyInfo{}, nil, fmt.Errorf("invalid 'keys' object in %s: k must be at least 1, got %d", name, keys.K)
 	}
 
-	// --- 2. Decode the Y Values and collect points ---
-	var points []Point
 	// Sort keys to ensure we get a consistent set of points if n > k
 	var sortedKeys []string
 	for keyStr := range rawData {
@@ -59,52 +241,236 @@ func solveForSecret(filePath string) (*big.Int, error) {
 			sortedKeys = append(sortedKeys, keyStr)
 		}
 	}
-	sort.Strings(sortedKeys)
+	sortKeysNumerically(sortedKeys)
+	return keys, sortedKeys, nil
+}
 
-	// We only need 'k' points to define the polynomial
-	for _, keyStr := range sortedKeys {
-		if len(points) >= keys.K {
-			break
-		}
+// SolveFromRawMap reconstructs the secret from a test case that has already
+// been unmarshalled into its top-level keys, such as one assembled in
+// memory, without encoding it back to JSON first. Shares are taken in
+// numeric key order.
+func SolveFromRawMap(raw map[string]json.RawMessage) (*big.Int, error) {
+	keys, sortedKeys, err := parseRawMap(raw, "test case")
+	if err != nil {
+		return nil, err
+	}
+	points, err := decodePoints(keys, raw, sortedKeys)
+	if err != nil {
+		return nil, err
+	}
+	secret, _, err := SolveWithTrace(points, keys.K)
+	return secret, err
+}
 
-		// The key is the 'x' coordinate
-		x, ok := new(big.Int).SetString(keyStr, 10)
-		if !ok {
-			return nil, fmt.Errorf("failed to parse x-coordinate '%s' to integer", keyStr)
-		}
+// documentOrder makes readTestCase return share keys in the order they
+// appear in the file rather than sorted, set by --no-sort.
+var documentOrder bool
 
-		// Decode the corresponding 'y' coordinate
-		var rootVal RootValue
-		if err := json.Unmarshal(rawData[keyStr], &rootVal); err != nil {
-			return nil, fmt.Errorf("failed to parse root object for key '%s': %w", keyStr, err)
-		}
+// stopAtK makes decodePoints stop once it has k points, set by --stop-at-k.
+// It only takes effect together with documentOrder: the first k shares of
+// the file are then all that is used, so the rest are never decoded.
+var stopAtK bool
 
-		base, err := strconv.Atoi(rootVal.Base)
+// documentKeys streams the top-level object in jsonData and returns its
+// share keys in document order. A key repeated later in the document keeps
+// its first position.
+func documentKeys(jsonData []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(jsonData))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, fmt.Errorf("expected a JSON object")
+	}
+
+	var keys []string
+	seen := make(map[string]bool)
+	for dec.More() {
+		tok, err := dec.Token()
 		if err != nil {
-			return nil, fmt.Errorf("invalid base '%s' for key '%s'", rootVal.Base, keyStr)
+			return nil, err
+		}
+		key := tok.(string)
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return nil, err
 		}
+		if key != "keys" && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
 
-		y, ok := new(big.Int).SetString(rootVal.Value, base)
-		if !ok {
-			return nil, fmt.Errorf("failed to parse y-value '%s' in base %d for key '%s'", rootVal.Value, base, keyStr)
+// sortKeysNumerically orders share keys by their numeric value, so "10"
+// comes after "9". Keys that are not numbers sort after all numeric ones,
+// in string order.
+func sortKeysNumerically(keys []string) {
+	values := make(map[string]*big.Rat, len(keys))
+	for _, key := range keys {
+		if v, ok := new(big.Rat).SetString(key); ok {
+			values[key] = v
 		}
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		vi, vj := values[keys[i]], values[keys[j]]
+		switch {
+		case vi != nil && vj != nil:
+			if c := vi.Cmp(vj); c != 0 {
+				return c < 0
+			}
+			return keys[i] < keys[j]
+		case vi != nil:
+			return true
+		case vj != nil:
+			return false
+		}
+		return keys[i] < keys[j]
+	})
+}
+
+// decodeShare unmarshals the root object stored under keyStr and decodes
+// it into a point with its y-value, optional weight and, with
+// --preserve-width, byte width. The caller fills in X.
+func decodeShare(keyStr string, raw json.RawMessage) (Point, error) {
+	var rootVal RootValue
+	if err := json.Unmarshal(raw, &rootVal); err != nil {
+		return Point{}, fmt.Errorf("failed to parse root object for key '%s' (got %s): %w", keyStr, snippet(raw), err)
+	}
+	weight, err := shareWeight(keyStr, rootVal)
+	if err != nil {
+		return Point{}, err
+	}
+	y, err := decodeY(keyStr, rootVal)
+	if err != nil {
+		return Point{}, err
+	}
+	width, err := shareWidth(keyStr, rootVal)
+	if err != nil {
+		return Point{}, err
+	}
+	return Point{Y: y, Weight: weight, Width: width}, nil
+}
+
+// maxSnippet is how much of a malformed share object is quoted in errors.
+const maxSnippet = 80
 
-		points = append(points, Point{X: x, Y: y})
+// snippet renders raw JSON on one line for an error message, truncated to
+// maxSnippet bytes.
+func snippet(raw json.RawMessage) string {
+	s := strings.Join(strings.Fields(string(raw)), " ")
+	if len(s) > maxSnippet {
+		s = s[:maxSnippet] + "..."
 	}
+	return s
+}
 
-	if len(points) < keys.K {
-		return nil, fmt.Errorf("not enough points provided: need %d, got %d", keys.K, len(points))
+// SolveWithTrace reconstructs the secret from the first k points and also
+// returns the running partial sum after each Lagrange term. The last element
+// of the trace is the secret itself.
+func SolveWithTrace(points []Point, k int) (*big.Int, []*big.Rat, error) {
+	secret, terms, err := solveTerms(points, k)
+	if err != nil {
+		return nil, nil, err
+	}
+	trace := make([]*big.Rat, len(terms))
+	for j, t := range terms {
+		trace[j] = t.Sum
 	}
+	return secret, trace, nil
+}
 
-	// --- 3. Find the Secret (C) using Lagrange Interpolation ---
+// SolveWhere reconstructs the secret from the first k points that satisfy
+// pred, erroring if fewer than k of them do.
+func SolveWhere(points []Point, k int, pred func(Point) bool) (*big.Int, error) {
+	selected := filterPoints(points, pred)
+	if len(selected) < k {
+		return nil, fmt.Errorf("not enough points match: need %d, got %d", k, len(selected))
+	}
+
+	secret, _, err := SolveWithTrace(selected, k)
+	return secret, err
+}
+
+// filterPoints returns the points that satisfy pred, in their original order.
+func filterPoints(points []Point, pred func(Point) bool) []Point {
+	var selected []Point
+	for _, p := range points {
+		if pred(p) {
+			selected = append(selected, p)
+		}
+	}
+	return selected
+}
+
+// parseXMod parses an --x-mod "A:B" residue class, x ≡ B (mod A), into a
+// predicate over points.
+func parseXMod(s string) (func(Point) bool, error) {
+	modStr, remStr, ok := strings.Cut(s, ":")
+	if !ok {
+		return nil, fmt.Errorf("residue class %q must have the form A:B", s)
+	}
+	mod, ok := new(big.Int).SetString(strings.TrimSpace(modStr), 10)
+	if !ok || mod.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid modulus %q: must be a positive integer", modStr)
+	}
+	rem, ok := new(big.Int).SetString(strings.TrimSpace(remStr), 10)
+	if !ok || rem.Sign() < 0 || rem.Cmp(mod) >= 0 {
+		return nil, fmt.Errorf("invalid residue %q: must be between 0 and %s", remStr, new(big.Int).Sub(mod, big.NewInt(1)).String())
+	}
+	return func(p Point) bool {
+		return new(big.Int).Mod(p.X, mod).Cmp(rem) == 0
+	}, nil
+}
+
+// solveTerms reconstructs the secret from the first k points and returns the
+// individual Lagrange terms it was built from.
+func solveTerms(points []Point, k int) (*big.Int, []LagrangeTerm, error) {
+	if k < 1 {
+		return nil, nil, fmt.Errorf("k must be at least 1, got %d", k)
+	}
+	if len(points) < k {
+		return nil, nil, fmt.Errorf("not enough points provided: need %d, got %d", k, len(points))
+	}
+
+	terms, err := lagrangeTerms(points[:k])
+	if err != nil {
+		return nil, nil, err
+	}
+	totalSum := terms[len(terms)-1].Sum
+
+	// The final result 'c' must be an integer, as per the problem constraints.
+	if !totalSum.IsInt() {
+		return nil, nil, fmt.Errorf("fatal: final result is not an integer, something went wrong with the calculation. Result: %s", totalSum.FloatString(5))
+	}
+
+	// Return the integer part of the result.
+	return totalSum.Num(), terms, nil
+}
+
+// LagrangeTerm records one step of the Lagrange reconstruction of f(0).
+type LagrangeTerm struct {
+	X *big.Int
+	Y *big.Int
+	// Numerator and Denominator are the unreduced products making up
+	// L_j(0) = Π x_i / Π (x_i - x_j); Basis is that fraction in lowest terms.
+	Numerator   *big.Int
+	Denominator *big.Int
+	Basis       *big.Rat
+	// Sum is the running value of f(0) after adding y_j * L_j(0).
+	Sum *big.Rat
+}
+
+// lagrangeTerms interpolates through all of points and returns each term of
+// f(0) together with the running sum after it has been added.
+func lagrangeTerms(points []Point) ([]LagrangeTerm, error) {
 	// The secret c is the value of the polynomial at x=0, i.e., f(0).
 	// c = f(0) = Σ [y_j * L_j(0)]
 	// L_j(0) = Π [x_i / (x_i - x_j)] for i != j
 
 	// We use rational numbers (big.Rat) for calculations to avoid precision loss from division.
 	totalSum := new(big.Rat) // Initializes to 0/1
+	terms := make([]LagrangeTerm, 0, len(points))
 
-	for j := 0; j < keys.K; j++ {
+	for j := range points {
 		xj := points[j].X
 		yj := points[j].Y
 
@@ -112,17 +478,20 @@ func solveForSecret(filePath string) (*big.Int, error) {
 		numerator := big.NewInt(1)
 		denominator := big.NewInt(1)
 
-		for i := 0; i < keys.K; i++ {
+		for i := range points {
 			if i == j {
 				continue
 			}
 			xi := points[i].X
-			
+
 			// Numerator term: x_i
 			numerator.Mul(numerator, xi)
 
 			// Denominator term: (x_i - x_j)
 			diff := new(big.Int).Sub(xi, xj)
+			if diff.Sign() == 0 {
+				return nil, fmt.Errorf("duplicate x-coordinate %s", xi.String())
+			}
 			denominator.Mul(denominator, diff)
 		}
 
@@ -130,34 +499,658 @@ func solveForSecret(filePath string) (*big.Int, error) {
 		// The full term for the sum is y_j * L_j(0).
 		// We can multiply y_j into the numerator.
 		termNumerator := new(big.Int).Mul(yj, numerator)
-		
+
 		// Create the rational number for this term
 		term := new(big.Rat).SetFrac(termNumerator, denominator)
-		
-		// Add it to our total sum
+
+		// Add it to our total sum and record the running value
 		totalSum.Add(totalSum, term)
+		terms = append(terms, LagrangeTerm{
+			X:           xj,
+			Y:           yj,
+			Numerator:   numerator,
+			Denominator: denominator,
+			Basis:       new(big.Rat).SetFrac(numerator, denominator),
+			Sum:         new(big.Rat).Set(totalSum),
+		})
 	}
+	return terms, nil
+}
 
-	// The final result 'c' must be an integer, as per the problem constraints.
-	if !totalSum.IsInt() {
-		return nil, fmt.Errorf("fatal: final result is not an integer, something went wrong with the calculation. Result: %s", totalSum.FloatString(5))
-	}
+// options holds the command-line settings that affect how each file is
+// solved.
+type options struct {
+	// load reads the test case named by each input and returns it with
+	// the hex SHA-256 of the bytes it was decoded from, or "" when the
+	// shares did not come from a single file. It is loadTestCaseDigest
+	// unless the shares come from somewhere other than files.
+	load           func(file string) (KeyInfo, []Point, string, error)
+	params         *paramsCheck // nil unless --consistent-params
+	dumpPoints     string
+	dumpAllPoints  bool
+	consensus      bool
+	prime          *big.Int // nil outside field mode
+	skipPrimeCheck bool
+	rationalX      bool
+	gf256          bool
+	method         Method
+	xFilter        func(Point) bool // nil unless --x-mod
+	gfPoly         uint64           // zero unless --gf2m-poly
+	injectFault    *big.Int         // nil unless --inject-fault
+	roundTolerance *big.Rat         // nil unless --round-tolerance
+	gfDegree       int
+	explainJSON    bool
+	requireVerify  bool
+	// showDeterminant solves with the matrix method and records the
+	// Vandermonde determinant, for --show-determinant.
+	showDeterminant bool
+	evalFrom        *big.Int // nil unless --eval-range
+	evalTo          *big.Int
 
-	// Return the integer part of the result.
-	return totalSum.Num(), nil
+	// The rest are applied by processFile and Run rather than solveFile.
+	continuedFraction  bool
+	intWidth           int
+	unpackLengthPrefix bool
+	expectedDir        string
+	continueOnError    bool
+	stats              bool
 }
 
 func main() {
-	testFiles := []string{"testcase1.json", "testcase2.json"}
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "gen":
+			if err := runGen(os.Args[2:]); err != nil {
+				log.Fatalf("gen: %v", err)
+			}
+			return
+		case "serve":
+			if err := runServe(os.Args[2:]); err != nil {
+				log.Fatalf("serve: %v", err)
+			}
+			return
+		case "bench":
+			if err := runBench(os.Args[2:]); err != nil {
+				log.Fatalf("bench: %v", err)
+			}
+			return
+		}
+	}
+
+	consistentParams := flag.Bool("consistent-params", false, "require every file to declare the same n and k as the first one")
+	dumpPoints := flag.String("dump-points", "", "write the decoded points of the selected k shares to this JSON `file`")
+	dumpAllPoints := flag.Bool("dump-all-points", false, "with --dump-points, write every decoded point instead of only the first k")
+	consensus := flag.Bool("consensus", false, "vote over every k-subset of the shares instead of using the first k")
+	primeStr := flag.String("prime", "", "reconstruct over the field GF(`p`) instead of the rationals")
+	skipPrimeCheck := flag.Bool("skip-prime-check", false, "trust --prime without testing it for primality")
+	format := flag.String("format", "text", "output `format`: text or json")
+	templateStr := flag.String("template", "", "Go text/template for each result line, e.g. '{{.File}},{{.Secret}}'")
+	digits := flag.String("alphabet", "", "custom digit `alphabet` for the extended bases 37-62 (default 0-9a-zA-Z)")
+	flag.IntVar(&outputBase, "output-base", 10, "print secrets in this `base` (2-62)")
+	expectedDir := flag.String("expected-dir", "", "compare each secret with NAME.expected in `dir`")
+	bases := flag.String("allowed-bases", "", "comma-separated `list` of bases shares may declare (default any)")
+	flag.IntVar(&maxBits, "max-bits", 0, "reject any decoded coordinate longer than `bits` bits (0 means no limit)")
+	flag.IntVar(&secretWidth, "secret-width", 0, "zero-pad secrets to at least `n` characters (0 disables)")
+	rationalX := flag.Bool("rational-x", false, "accept fractional x-coordinates such as \"1/2\" and allow a fractional secret")
+	explainJSON := flag.Bool("explain-json", false, "print the Lagrange derivation of each secret as JSON")
+	gf256 := flag.Bool("gf256", false, "treat shares as byte strings (hexbytes or base64) shared byte-wise over GF(256)")
+	continueOnError := flag.Bool("continue-on-error", false, "keep processing the remaining files after a failure")
+	errorFile := flag.String("error-file", "", "write every failure to this JSON `file`; implies --continue-on-error")
+	summaryOnly := flag.Bool("summary-only", false, "print only a final tally of solved and failed files")
+	evalRangeStr := flag.String("eval-range", "", "after solving, print f(x) for every integer x in the inclusive `a:b` range")
+	requireVerified := flag.Bool("require-verified", false, "fail unless redundant shares exist and all lie on the reconstructed polynomial")
+	fromEnv := flag.String("from-env", "", "read shares from environment variables starting with this `prefix` instead of files")
+	k := flag.Int("k", 0, "threshold k, for inputs that carry no 'keys' object")
+	n := flag.Int("n", 0, "total share count n for CSV input (default the number of rows)")
+	inputFormat := flag.String("input-format", "auto", "input `format`: auto (by extension), json or csv")
+	flag.BoolVar(&documentOrder, "no-sort", false, "use the first k shares in file order instead of sorted by x")
+	flag.BoolVar(&stopAtK, "stop-at-k", false, "with --no-sort, stop decoding a JSON file once k shares are read")
+	methodStr := flag.String("method", string(MethodAuto), "interpolation `method`: auto, lagrange, newton or matrix")
+	flag.IntVar(&intPathThreshold, "int-path-threshold", intPathThreshold, "use the integer-only solver when k is at least this `k`")
+	limit := flag.Int("limit", 0, "process at most `N` files (0 means all)")
+	offset := flag.Int("offset", 0, "skip the first `M` files")
+	showStats := flag.Bool("stats", false, "report wall time and allocations for the whole run")
+	guessBase := flag.String("guess-base", "", "list every base the share with this `key` parses in, without solving")
+	dryParseFlag := flag.Bool("dry-parse", false, "print how each input was parsed and decoded as JSON, without solving")
+	continuedFraction := flag.Bool("continued-fraction", false, "with --rational-x, also print the secret's continued-fraction expansion")
+	intWidth := flag.Int("int-width", 0, "read the secret as a signed two's-complement integer of this many `bits` (e.g. 8, 16, 32, 64)")
+	unpackLengthPrefix := flag.Bool("unpack-length-prefix", false, "decode the secret as a length byte followed by that many bytes of UTF-8 text")
+	gfPolyStr := flag.String("gf2m-poly", "", "solve over GF(2^m) defined by this irreducible `polynomial` bitmask, e.g. 0x13")
+	roundToleranceStr := flag.String("round-tolerance", "", "accept a secret within this `distance` of an integer, rounding it (default exact)")
+	injectFaultStr := flag.String("inject-fault", "", "testing aid: corrupt the share at this `x` before solving")
+	xMod := flag.String("x-mod", "", "only use shares with x ≡ B (mod A), given as `A:B`")
+	flag.BoolVar(&preserveWidth, "preserve-width", false, "record the byte width of hexbytes and base64 shares and include it in dumped points")
+	flag.IntVar(&readRetries, "retry", 0, "retry a failed input read up to `N` times")
+	flag.DurationVar(&retryDelay, "retry-delay", retryDelay, "wait this long before the first retry, doubling each time")
+	flag.BoolVar(&strict, "strict", false, "treat warnings as errors")
+	flag.BoolVar(&roundtripCheck, "roundtrip-check", false, "warn when a positional y-value does not re-encode to the same digits in its base")
+	showDeterminant := flag.Bool("show-determinant", false, "with --method matrix, also print the determinant of the Vandermonde matrix")
+	showTUI := flag.Bool("tui", false, "show a live progress line while solving (plain output when stdout is not a terminal)")
+	sortBy := flag.String("sort-by", "file", "order results by `key`: file or secret")
+	collect := flag.Bool("collect", false, "read every input file as part of one share set, such as the files of gen --split-output-dir")
+	recursive := flag.Bool("recursive", false, "process every .json file under directory arguments, grouped by directory")
+	flag.Parse()
+
+	if err := SetAlphabet(*digits); err != nil {
+		log.Fatalf("Invalid --alphabet: %v", err)
+	}
+	if _, err := FormatBase(new(big.Int), outputBase); err != nil {
+		log.Fatalf("Invalid --output-base: %v", err)
+	}
+	if *templateStr != "" {
+		if err := SetResultTemplate(*templateStr); err != nil {
+			log.Fatalf("Invalid --template: %v", err)
+		}
+	}
+	if err := SetAllowedBases(*bases); err != nil {
+		log.Fatalf("Invalid --allowed-bases: %v", err)
+	}
+	if *continuedFraction && !*rationalX {
+		log.Fatalf("--continued-fraction needs --rational-x, the mode that allows a fractional secret")
+	}
+	if stopAtK && !documentOrder {
+		log.Fatalf("--stop-at-k needs --no-sort: in sorted order every share must be read to find the first k")
+	}
+	if stopAtK && (*consensus || *requireVerified || *dumpAllPoints || *xMod != "") {
+		log.Fatalf("--stop-at-k cannot be combined with --consensus, --require-verified, --dump-all-points or --x-mod, which need every share")
+	}
+	if *intWidth < 0 {
+		log.Fatalf("Invalid --int-width: must not be negative, got %d", *intWidth)
+	}
+	if readRetries < 0 {
+		log.Fatalf("Invalid --retry: must not be negative, got %d", readRetries)
+	}
+	if maxBits < 0 {
+		log.Fatalf("Invalid --max-bits: must not be negative, got %d", maxBits)
+	}
+	if secretWidth < 0 {
+		log.Fatalf("Invalid --secret-width: must not be negative, got %d", secretWidth)
+	}
+
+	testFiles, err := expandInputs(flag.Args(), *recursive)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if len(flag.Args()) == 0 {
+		testFiles = []string{"testcase1.json", "testcase2.json"}
+	}
+	if *limit < 0 || *offset < 0 {
+		log.Fatalf("--limit and --offset must not be negative")
+	}
+	testFiles = windowFiles(testFiles, *offset, *limit)
+	if *dumpPoints != "" && len(testFiles) != 1 && !*collect {
+		log.Fatalf("--dump-points needs exactly one input file, got %d", len(testFiles))
+	}
+	if *sortBy != "file" && *sortBy != "secret" {
+		log.Fatalf("Unknown --sort-by %q: want file or secret", *sortBy)
+	}
+	if *sortBy == "secret" && *recursive {
+		log.Fatalf("--sort-by=secret cannot be combined with --recursive")
+	}
+	if *format != "text" && *format != "json" {
+		log.Fatalf("Unknown --format %q: want text or json", *format)
+	}
 
-	fmt.Println("Catalog Placements Assignment - Shamir's Secret Sharing")
-	fmt.Println("======================================================")
+	opts := &options{
+		load:           loadTestCaseDigest,
+		dumpPoints:     *dumpPoints,
+		dumpAllPoints:  *dumpAllPoints,
+		consensus:      *consensus,
+		rationalX:      *rationalX,
+		gf256:          *gf256,
+		explainJSON:    *explainJSON,
+		skipPrimeCheck: *skipPrimeCheck,
 
-	for _, file := range testFiles {
-		secret, err := solveForSecret(file)
+		continuedFraction:  *continuedFraction,
+		intWidth:           *intWidth,
+		unpackLengthPrefix: *unpackLengthPrefix,
+		expectedDir:        *expectedDir,
+		continueOnError:    *continueOnError || *errorFile != "",
+		stats:              *showStats,
+	}
+	if *explainJSON && (*consensus || *primeStr != "" || *rationalX) {
+		log.Fatalf("--explain-json cannot be combined with --consensus, --prime or --rational-x")
+	}
+	if *rationalX && (*consensus || *primeStr != "" || *dumpPoints != "") {
+		log.Fatalf("--rational-x cannot be combined with --consensus, --prime or --dump-points")
+	}
+	if *gf256 && (*consensus || *primeStr != "" || *rationalX || *explainJSON || *dumpPoints != "") {
+		log.Fatalf("--gf256 cannot be combined with --consensus, --prime, --rational-x, --explain-json or --dump-points")
+	}
+	if opts.method, err = parseMethod(*methodStr); err != nil {
+		log.Fatalf("Invalid --method: %v", err)
+	}
+	if *showDeterminant {
+		if opts.method != MethodMatrix {
+			log.Fatalf("--show-determinant needs --method matrix")
+		}
+		if *consensus || *primeStr != "" || *rationalX || *gf256 || *gfPolyStr != "" || *explainJSON || *roundToleranceStr != "" {
+			log.Fatalf("--show-determinant cannot be combined with --consensus, --prime, --rational-x, --gf256, --gf2m-poly, --explain-json or --round-tolerance")
+		}
+		opts.showDeterminant = true
+	}
+	if *inputFormat != "auto" && *inputFormat != "json" && *inputFormat != "csv" {
+		log.Fatalf("Unknown --input-format %q: want auto, json or csv", *inputFormat)
+	}
+	if *inputFormat != "json" {
+		threshold, total, forced := *k, *n, *inputFormat
+		opts.load = func(file string) (KeyInfo, []Point, string, error) {
+			if isCSVInput(file, forced) {
+				return loadCSVTestCase(file, threshold, total)
+			}
+			return loadTestCaseDigest(file)
+		}
+	}
+	if *gfPolyStr != "" {
+		if *consensus || *primeStr != "" || *rationalX || *gf256 || *explainJSON {
+			log.Fatalf("--gf2m-poly cannot be combined with --consensus, --prime, --rational-x, --gf256 or --explain-json")
+		}
+		if opts.gfPoly, opts.gfDegree, err = parseGFPoly(*gfPolyStr); err != nil {
+			log.Fatalf("Invalid --gf2m-poly: %v", err)
+		}
+	}
+	if *roundToleranceStr != "" {
+		if *consensus || *primeStr != "" || *gf256 || *explainJSON || opts.gfPoly != 0 {
+			log.Fatalf("--round-tolerance cannot be combined with --consensus, --prime, --gf256, --gf2m-poly or --explain-json")
+		}
+		tol, ok := new(big.Rat).SetString(*roundToleranceStr)
+		if !ok || tol.Sign() < 0 {
+			log.Fatalf("Invalid --round-tolerance %q: want a non-negative number such as 1e-9", *roundToleranceStr)
+		}
+		if tol.Sign() > 0 {
+			opts.roundTolerance = tol
+		}
+	}
+	if *injectFaultStr != "" {
+		if *rationalX || *gf256 {
+			log.Fatalf("--inject-fault cannot be combined with --rational-x or --gf256")
+		}
+		var ok bool
+		if opts.injectFault, ok = new(big.Int).SetString(*injectFaultStr, 10); !ok {
+			log.Fatalf("Invalid --inject-fault %q: want an integer x-coordinate", *injectFaultStr)
+		}
+	}
+	if *xMod != "" {
+		if *rationalX || *gf256 {
+			log.Fatalf("--x-mod cannot be combined with --rational-x or --gf256")
+		}
+		if opts.xFilter, err = parseXMod(*xMod); err != nil {
+			log.Fatalf("Invalid --x-mod: %v", err)
+		}
+	}
+	if *consistentParams {
+		opts.params = &paramsCheck{}
+	}
+	if *fromEnv != "" {
+		if *rationalX {
+			log.Fatalf("--from-env cannot be combined with --rational-x")
+		}
+		prefix, threshold := *fromEnv, *k
+		opts.load = func(string) (KeyInfo, []Point, string, error) {
+			keys, points, err := loadEnvShares(prefix, os.Environ(), threshold)
+			return keys, points, "", err
+		}
+		testFiles = []string{"$" + prefix + "*"}
+	}
+	if *collect {
+		if *rationalX || *gf256 || *fromEnv != "" || *recursive {
+			log.Fatalf("--collect cannot be combined with --rational-x, --gf256, --from-env or --recursive")
+		}
+		files := testFiles
+		opts.load = func(string) (KeyInfo, []Point, string, error) {
+			keys, points, err := loadShareFiles(files)
+			return keys, points, "", err
+		}
+		testFiles = []string{strings.Join(files, ",")}
+	}
+	if *requireVerified {
+		if *primeStr != "" || *rationalX {
+			log.Fatalf("--require-verified cannot be combined with --prime or --rational-x")
+		}
+		opts.requireVerify = true
+	}
+	if *evalRangeStr != "" {
+		if *primeStr != "" || *rationalX {
+			log.Fatalf("--eval-range cannot be combined with --prime or --rational-x")
+		}
+		opts.evalFrom, opts.evalTo, err = parseEvalRange(*evalRangeStr)
 		if err != nil {
-			log.Fatalf("Error processing %s: %v", file, err)
+			log.Fatalf("Invalid --eval-range: %v", err)
+		}
+	}
+	if *primeStr != "" {
+		var ok bool
+		opts.prime, ok = new(big.Int).SetString(*primeStr, 10)
+		if !ok {
+			log.Fatalf("Invalid --prime %q", *primeStr)
+		}
+		if *consensus {
+			log.Fatalf("--consensus is not supported together with --prime")
+		}
+	}
+
+	if *guessBase != "" {
+		for _, file := range testFiles {
+			if err := printBaseGuesses(file, *guessBase); err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+		}
+		return
+	}
+	if *dryParseFlag {
+		for _, file := range testFiles {
+			parsed, err := dryParse(file)
+			if err != nil {
+				log.Fatalf("Error parsing %s: %v", file, err)
+			}
+			if err := writeJSON(os.Stdout, parsed); err != nil {
+				log.Fatalf("Error writing JSON: %v", err)
+			}
+		}
+		return
+	}
+
+	if *format == "text" && !*explainJSON && resultTemplate == nil {
+		fmt.Println("Catalog Placements Assignment - Shamir's Secret Sharing")
+		fmt.Println("======================================================")
+	}
+
+	// Every output is a sink: per-result text or JSON, or else a summary,
+	// explanations or per-directory groups written once the run is over.
+	var sink ResultSink
+	switch {
+	case *summaryOnly:
+		sink = &summarySink{w: os.Stdout, json: *format == "json"}
+	case *explainJSON:
+		sink = &explainSink{w: os.Stdout}
+	case *recursive:
+		sink = &dirSink{w: os.Stdout, json: *format == "json"}
+	case *format == "text":
+		sink = &textSink{w: os.Stdout}
+	default:
+		sink = &jsonSink{w: os.Stdout}
+	}
+	// Sorting by secret needs every result first, and the progress line
+	// would be overwritten by results, so in both cases results are held
+	// back until the run is over.
+	var tui *progressTUI
+	if *showTUI && !*recursive {
+		tui = newProgressTUI(len(testFiles))
+	}
+	if *sortBy == "secret" || tui != nil {
+		sink = &deferredSink{next: sink, bySecret: *sortBy == "secret"}
+	}
+	if !*summaryOnly {
+		sink = &failureLog{w: os.Stderr, next: sink}
+	}
+	if tui != nil {
+		sink = &progressSink{tui: tui, next: sink}
+	}
+
+	summary, err := Run(testFiles, opts, sink)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *errorFile != "" {
+		if err := writeErrorFile(*errorFile, summary.Failures); err != nil {
+			log.Fatalf("Error writing %s: %v", *errorFile, err)
 		}
-		fmt.Printf("Secret for %s: %s\n", file, secret.String())
 	}
-}
\ No newline at end of file
+	if summary.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// solveFile loads one test case and reconstructs its secret as configured
+// by opts.
+func solveFile(file string, opts *options) (Result, error) {
+	if opts.rationalX {
+		return solveRatFile(file, opts)
+	}
+	if opts.gf256 {
+		return solveGF256File(file, opts)
+	}
+
+	keys, points, digest, err := opts.load(file)
+	if err != nil {
+		return Result{}, inputError{err}
+	}
+	if opts.injectFault != nil {
+		if err := injectFault(points, opts.injectFault); err != nil {
+			return Result{}, err
+		}
+		fmt.Fprintf(os.Stderr, "Warning: fault injected into the share at x=%s of %s for testing; the result may be wrong\n", opts.injectFault.String(), file)
+	}
+
+	if opts.params != nil {
+		if err := opts.params.check(file, keys); err != nil {
+			return Result{}, err
+		}
+	}
+	if opts.xFilter != nil {
+		points = filterPoints(points, opts.xFilter)
+		if len(points) < keys.K {
+			return Result{}, fmt.Errorf("not enough points match --x-mod: need %d, got %d", keys.K, len(points))
+		}
+	} else if keys.N != len(points) && !stopAtK {
+		if err := warnf("%s declares n=%d but has %d shares", file, keys.N, len(points)); err != nil {
+			return Result{}, err
+		}
+	}
+
+	if opts.dumpPoints != "" {
+		selected := points
+		if !opts.dumpAllPoints {
+			selected = points[:keys.K]
+		}
+		if err := writePoints(opts.dumpPoints, selected); err != nil {
+			return Result{}, err
+		}
+	}
+
+	if opts.requireVerify {
+		if err := requireVerified(points, keys.K); err != nil {
+			return Result{}, err
+		}
+	}
+
+	result := Result{File: file, N: keys.N, K: keys.K, InputSHA256: digest}
+	switch {
+	case opts.consensus:
+		cr, err := SolveByConsensus(points, keys.K)
+		if err != nil {
+			return Result{}, err
+		}
+		if cr.Secret == nil {
+			candidates := make([]string, len(cr.Candidates))
+			for i, c := range cr.Candidates {
+				candidates[i] = c.String()
+			}
+			return Result{}, fmt.Errorf("ambiguous: %d candidates with %d votes each: %s", len(cr.Candidates), cr.Votes, strings.Join(candidates, ", "))
+		}
+		result.Secret = cr.Secret
+		result.Confidence = &cr.Confidence
+	case opts.prime != nil:
+		if err := checkFieldSize(opts.prime, keys.N, points); err != nil {
+			return Result{}, err
+		}
+		if !opts.skipPrimeCheck && !isProbablyPrime(opts.prime) {
+			if err := warnf("modulus %s is not prime, field-mode reconstruction of %s may be wrong", opts.prime.String(), file); err != nil {
+				return Result{}, err
+			}
+		}
+		result.Secret, err = SolveMod(points, keys.K, opts.prime)
+		if err != nil {
+			return Result{}, err
+		}
+	case opts.gfPoly != 0:
+		result.Secret, err = SolveGFExt(points, keys.K, opts.gfPoly, opts.gfDegree)
+		if err != nil {
+			return Result{}, err
+		}
+	case opts.explainJSON:
+		result.Secret, result.Explanation, err = explainSecret(file, points, keys.K)
+		if err != nil {
+			return Result{}, err
+		}
+	case opts.roundTolerance != nil:
+		exact, err := lagrangeEval(points[:keys.K], new(big.Int))
+		if err != nil {
+			return Result{}, err
+		}
+		if result.Secret, err = roundSecret(exact, opts.roundTolerance); err != nil {
+			return Result{}, err
+		}
+		if !exact.IsInt() {
+			result.RoundedFrom = exact
+		}
+	case opts.showDeterminant:
+		coeffs, det, err := SolveLinearSystemWithDeterminant(points, keys.K)
+		if err != nil {
+			return Result{}, err
+		}
+		if result.Secret, err = integerSecret(coeffs[0]); err != nil {
+			return Result{}, err
+		}
+		result.Determinant = det
+	default:
+		result.Secret, err = Solve(points, keys.K, opts.method)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	if opts.evalFrom != nil {
+		poly, err := ReconstructPolynomial(points, keys.K)
+		if err != nil {
+			return Result{}, err
+		}
+		result.Evaluations = evalRange(poly, opts.evalFrom, opts.evalTo)
+	}
+	return result, nil
+}
+
+// strict turns every warning into an error, set by --strict.
+var strict bool
+
+// roundSecret rounds an exact secret to the nearest integer for
+// --round-tolerance, erroring if it is further than tol from one.
+func roundSecret(exact, tol *big.Rat) (*big.Int, error) {
+	n, ok := roundNear(exact, tol)
+	if !ok {
+		return nil, fmt.Errorf("secret %s is not within --round-tolerance %s of an integer", exact.FloatString(10), tol.RatString())
+	}
+	return n, nil
+}
+
+// injectFault flips the lowest bit of the y-value of the share at x, for
+// --inject-fault. The point's Y is replaced, not modified in place.
+func injectFault(points []Point, x *big.Int) error {
+	for i, p := range points {
+		if p.X.Cmp(x) == 0 {
+			y := new(big.Int).Set(p.Y)
+			points[i].Y = y.SetBit(y, 0, y.Bit(0)^1)
+			return nil
+		}
+	}
+	return fmt.Errorf("--inject-fault: no share at x=%s", x.String())
+}
+
+// warnf prints a warning to stderr and processing continues. Under --strict
+// it prints nothing and returns the warning as an error instead, which the
+// caller must pass on.
+func warnf(format string, args ...any) error {
+	if strict {
+		return fmt.Errorf("warning treated as error: "+format, args...)
+	}
+	fmt.Fprintf(os.Stderr, "Warning: "+format+"\n", args...)
+	return nil
+}
+
+// solveRatFile is solveFile for --rational-x, where both the x-coordinates
+// and the secret may be fractions.
+func solveRatFile(file string, opts *options) (Result, error) {
+	keys, points, digest, err := loadRatTestCase(file)
+	if err != nil {
+		return Result{}, inputError{err}
+	}
+	if opts.params != nil {
+		if err := opts.params.check(file, keys); err != nil {
+			return Result{}, err
+		}
+	}
+
+	secret, err := SolveRat(points, keys.K)
+	if err != nil {
+		return Result{}, err
+	}
+	result := Result{File: file, N: keys.N, K: keys.K, InputSHA256: digest}
+	switch {
+	case secret.IsInt():
+		result.Secret = secret.Num()
+	case opts.roundTolerance != nil:
+		if result.Secret, err = roundSecret(secret, opts.roundTolerance); err != nil {
+			return Result{}, err
+		}
+		result.RoundedFrom = secret
+	default:
+		result.Fraction = secret
+	}
+	return result, nil
+}
+
+// solveGF256File is solveFile for --gf256, where every share is a byte
+// string and the secret is reconstructed byte by byte over GF(2^8).
+func solveGF256File(file string, opts *options) (Result, error) {
+	keys, shares, digest, err := loadGF256TestCase(file)
+	if err != nil {
+		return Result{}, inputError{err}
+	}
+	if opts.params != nil {
+		if err := opts.params.check(file, keys); err != nil {
+			return Result{}, err
+		}
+	}
+
+	secret, err := SolveGF256Shares(shares, keys.K)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{File: file, N: keys.N, K: keys.K, InputSHA256: digest, Secret: new(big.Int).SetBytes(secret), SecretBytes: secret}, nil
+}
+
+// paramsCheck remembers the n and k declared by the first file it sees and
+// rejects any later file that declares different ones.
+type paramsCheck struct {
+	first     *KeyInfo
+	firstFile string
+}
+
+// check records keys if file is the first one seen, and otherwise reports
+// an error naming the mismatch.
+func (c *paramsCheck) check(file string, keys KeyInfo) error {
+	if c.first == nil {
+		c.first, c.firstFile = &keys, file
+		return nil
+	}
+	if keys.N != c.first.N {
+		return fmt.Errorf("inconsistent params: %s declares n=%d but %s declares n=%d", file, keys.N, c.firstFile, c.first.N)
+	}
+	if keys.K != c.first.K {
+		return fmt.Errorf("inconsistent params: %s declares k=%d but %s declares k=%d", file, keys.K, c.firstFile, c.first.K)
+	}
+	return nil
+}
+
+// writePoints writes points to filePath as a JSON array of
+// {"x":"...","y":"..."} objects.
+func writePoints(filePath string, points []Point) error {
+	data, err := json.MarshalIndent(points, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal points: %w", err)
+	}
+	if err := os.WriteFile(filePath, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write points to %s: %w", filePath, err)
+	}
+	return nil
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeFile writes content to name in a fresh temporary directory and
+// returns its path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// cliArgsEnv carries the arguments for TestCLIHelper, which runs main in a
+// child process started by runCLI.
+const cliArgsEnv = "SHAMIR_TEST_CLI_ARGS"
+
+// runCLI runs the command line with args in a child copy of the test
+// binary and returns its stdout, its stderr and its exit code.
+func runCLI(t *testing.T, args ...string) (string, string, int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestCLIHelper$")
+	cmd.Env = append(os.Environ(), cliArgsEnv+"="+strings.Join(args, "\n"))
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	var exit *exec.ExitError
+	if err != nil && !errors.As(err, &exit) {
+		t.Fatal(err)
+	}
+	return stdout.String(), stderr.String(), cmd.ProcessState.ExitCode()
+}
+
+// TestCLIHelper is not a real test: it runs main when started by runCLI.
+func TestCLIHelper(t *testing.T) {
+	args, ok := os.LookupEnv(cliArgsEnv)
+	if !ok {
+		return
+	}
+	os.Args = append([]string{"shamir"}, strings.Split(args, "\n")...)
+	main()
+	os.Exit(0)
+}
+
+func TestConsistentParams(t *testing.T) {
+	first := writeFile(t, "a.json", `{
+		"keys": {"n": 3, "k": 2},
+		"1": {"base": "10", "value": "5"},
+		"2": {"base": "10", "value": "7"},
+		"3": {"base": "10", "value": "9"}
+	}`)
+	second := writeFile(t, "b.json", `{
+		"keys": {"n": 3, "k": 3},
+		"1": {"base": "10", "value": "5"},
+		"2": {"base": "10", "value": "9"},
+		"3": {"base": "10", "value": "15"}
+	}`)
+
+	opts := &options{load: loadTestCaseDigest, params: &paramsCheck{}}
+	if _, err := solveFile(first, opts); err != nil {
+		t.Fatalf("solveFile(%s): %v", first, err)
+	}
+	_, err := solveFile(second, opts)
+	if err == nil || !strings.Contains(err.Error(), "inconsistent params") || !strings.Contains(err.Error(), "k=3") {
+		t.Fatalf("solveFile(%s) = %v, want an inconsistent k error", second, err)
+	}
+}
+
+func TestDecodeShareMalformed(t *testing.T) {
+	raw := `{
+		"base": 10,
+		"value": "5"
+	}`
+	_, err := decodeShare("4", []byte(raw))
+	if err == nil {
+		t.Fatal("decodeShare accepted a numeric base")
+	}
+	msg := err.Error()
+	for _, want := range []string{"key '4'", `{ "base": 10, "value": "5" }`} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error %q does not contain %q", msg, want)
+		}
+	}
+
+	long := `{"base": "10", "value": ` + strings.Repeat("1", 200) + `}`
+	_, err = decodeShare("5", []byte(long))
+	if err == nil || !strings.Contains(err.Error(), "...") || strings.Contains(err.Error(), strings.Repeat("1", 100)) {
+		t.Errorf("error for a long share = %v, want a truncated snippet", err)
+	}
+}
+
+func TestSolveWhere(t *testing.T) {
+	points := polyPoints([]int64{3, 2, 1}, 1, 2, 3, 4, 5)
+	points[1].Y = new(big.Int).Add(points[1].Y, big.NewInt(7))
+
+	secret, err := SolveWhere(points, 3, func(p Point) bool { return p.X.Int64() != 2 })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Int64() != 3 {
+		t.Errorf("secret without x=2 = %s, want 3", secret.String())
+	}
+	if _, err := SolveWhere(points, 3, func(p Point) bool { return p.X.Int64() > 3 }); err == nil {
+		t.Error("SolveWhere succeeded with only two matching points")
+	}
+}
+
+func TestDocumentOrder(t *testing.T) {
+	// f(x) = 3 + 2x, except that the share at x=1 is corrupted: the first
+	// two shares by x include it, the first two in the file do not.
+	file := writeFile(t, "order.json", `{
+		"keys": {"n": 4, "k": 2},
+		"3": {"base": "10", "value": "9"},
+		"4": {"base": "10", "value": "11"},
+		"1": {"base": "10", "value": "6"},
+		"2": {"base": "10", "value": "7"}
+	}`)
+	solve := func(inOrder bool) string {
+		t.Helper()
+		documentOrder = inOrder
+		defer func() { documentOrder = false }()
+		secret, err := solveForSecret(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return secret.String()
+	}
+	if got := solve(false); got != "5" {
+		t.Errorf("sorted selection = %s, want 5 from x=1,2", got)
+	}
+	if got := solve(true); got != "3" {
+		t.Errorf("document-order selection = %s, want 3 from x=3,4", got)
+	}
+}
+
+func TestSolveFromRawMap(t *testing.T) {
+	raw := map[string]json.RawMessage{
+		"keys": json.RawMessage(`{"n": 4, "k": 3}`),
+		"10":   json.RawMessage(`{"base": "10", "value": "123"}`),
+		"2":    json.RawMessage(`{"base": "2", "value": "1011"}`),
+		"1":    json.RawMessage(`{"base": "10", "value": "6"}`),
+		"3":    json.RawMessage(`{"base": "16", "value": "12"}`),
+	}
+	// f(x) = 3 + 2x + x^2, from the shares at x = 1, 2, 3.
+	secret, err := SolveFromRawMap(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Int64() != 3 {
+		t.Fatalf("secret = %s, want 3", secret.String())
+	}
+
+	delete(raw, "keys")
+	if _, err := SolveFromRawMap(raw); err == nil {
+		t.Error("SolveFromRawMap accepted a map without 'keys'")
+	}
+}
+
+func TestDuplicateXVariants(t *testing.T) {
+	file := writeFile(t, "dup.json", `{
+		"keys": {"n": 3, "k": 2},
+		"1": {"base": "10", "value": "5"},
+		"03": {"base": "10", "value": "9"},
+		"3": {"base": "16", "value": "9"}
+	}`)
+	_, _, err := loadTestCase(file)
+	if err == nil || !strings.Contains(err.Error(), "duplicate x-coordinate 3") {
+		t.Fatalf("loadTestCase = %v, want a duplicate x-coordinate error", err)
+	}
+}
+
+func TestInputSHA256(t *testing.T) {
+	tests := []struct {
+		name, content, want string
+	}{
+		{
+			"case.json",
+			`{"keys": {"n": 2, "k": 2}, "1": {"base": "10", "value": "5"}, "2": {"base": "10", "value": "7"}}` + "\n",
+			"5e74db69413459839eb642da378e99e7cf36c581677bfab7376150af3fbc7a96",
+		},
+		{
+			"case.csv",
+			"x,base,value\n1,10,5\n2,10,7\n",
+			"abff9cb31da39a7974e63595549644d485f2f1fee7730f0623d78964f7abfd60",
+		},
+	}
+	opts := &options{load: func(file string) (KeyInfo, []Point, string, error) {
+		if isCSVInput(file, "auto") {
+			return loadCSVTestCase(file, 2, 0)
+		}
+		return loadTestCaseDigest(file)
+	}}
+	for _, tt := range tests {
+		result, err := solveFile(writeFile(t, tt.name, tt.content), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.InputSHA256 != tt.want {
+			t.Errorf("%s: InputSHA256 = %s, want %s", tt.name, result.InputSHA256, tt.want)
+		}
+	}
+}
+
+func TestStrict(t *testing.T) {
+	// Declares n=3 but has only two shares, which is only a warning.
+	file := writeFile(t, "short.json", `{
+		"keys": {"n": 3, "k": 2},
+		"1": {"base": "10", "value": "5"},
+		"2": {"base": "10", "value": "7"}
+	}`)
+	opts := &options{load: loadTestCaseDigest}
+	result, err := solveFile(file, opts)
+	if err != nil {
+		t.Fatalf("without --strict: %v", err)
+	}
+	if result.Secret.Int64() != 3 {
+		t.Errorf("secret = %s, want 3", result.Secret.String())
+	}
+
+	strict = true
+	defer func() { strict = false }()
+	_, err = solveFile(file, opts)
+	if err == nil || !strings.Contains(err.Error(), "declares n=3 but has 2 shares") {
+		t.Fatalf("with --strict: got %v, want the n mismatch as an error", err)
+	}
+}
+
+func TestParseXMod(t *testing.T) {
+	points := polyPoints([]int64{3, 2}, 1, 2, 3, 4, 5, 6, 7)
+	tests := []struct {
+		class string
+		want  []int64
+	}{
+		{"3:0", []int64{3, 6}},
+		{"3:1", []int64{1, 4, 7}},
+		{"3:2", []int64{2, 5}},
+		{"1:0", []int64{1, 2, 3, 4, 5, 6, 7}},
+	}
+	for _, tt := range tests {
+		pred, err := parseXMod(tt.class)
+		if err != nil {
+			t.Errorf("parseXMod(%q): %v", tt.class, err)
+			continue
+		}
+		var xs []int64
+		for _, p := range filterPoints(points, pred) {
+			xs = append(xs, p.X.Int64())
+		}
+		if !reflect.DeepEqual(xs, tt.want) {
+			t.Errorf("x ≡ %s: selected %v, want %v", tt.class, xs, tt.want)
+		}
+	}
+	for _, bad := range []string{"3", "0:0", "3:3", "3:-1"} {
+		if _, err := parseXMod(bad); err == nil {
+			t.Errorf("parseXMod(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestStopAtK(t *testing.T) {
+	// The third share in the file is malformed, so decoding fails if it is
+	// ever reached.
+	file := writeFile(t, "stop.json", `{
+		"keys": {"n": 3, "k": 2},
+		"3": {"base": "10", "value": "9"},
+		"4": {"base": "10", "value": "11"},
+		"1": {"base": "10", "value": "not a number"}
+	}`)
+	if _, _, err := loadTestCase(file); err == nil {
+		t.Fatal("loadTestCase decoded the malformed share without --stop-at-k")
+	}
+
+	documentOrder, stopAtK = true, true
+	defer func() { documentOrder, stopAtK = false, false }()
+	_, points, err := loadTestCase(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 2 || points[0].X.Int64() != 3 || points[1].X.Int64() != 4 {
+		t.Errorf("decoded %v, want only the shares at x=3 and x=4", points)
+	}
+
+	parsed, err := dryParse(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.Points) != 2 || parsed.Points[0].X != "3" || parsed.Points[1].X != "4" {
+		t.Errorf("dryParse reported %+v, want only the shares at x=3 and x=4", parsed.Points)
+	}
+}
+
+func TestSolveWithTrace(t *testing.T) {
+	// f(x) = 3 + 2x + x^2 at x = 1, 2, 3: the Lagrange terms at 0 are 18,
+	// -33 and 18.
+	points := polyPoints([]int64{3, 2, 1}, 1, 2, 3, 4)
+	secret, trace, err := SolveWithTrace(points, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sums []string
+	for _, s := range trace {
+		sums = append(sums, s.RatString())
+	}
+	if !reflect.DeepEqual(sums, []string{"18", "-15", "3"}) {
+		t.Errorf("trace = %v, want [18 -15 3]", sums)
+	}
+	last := trace[len(trace)-1]
+	if !last.IsInt() || last.Num().Cmp(secret) != 0 {
+		t.Errorf("last partial sum %s differs from the secret %s", last.RatString(), secret.String())
+	}
+	for _, pts := range [][]Point{randomPoints(3, 5, 5), randomPoints(4, 12, 12)} {
+		secret, trace, err := SolveWithTrace(pts, len(pts))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if last := trace[len(trace)-1]; len(trace) != len(pts) || !last.IsInt() || last.Num().Cmp(secret) != 0 {
+			t.Errorf("k=%d: %d partial sums ending in %s, want %d ending in the secret %s", len(pts), len(trace), last.RatString(), len(pts), secret.String())
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// SolveLinearSystem finds the coefficients a_0..a_{k-1} (constant term
+// first) of the polynomial through the first k points by solving the
+// Vandermonde system Σ a_i x_j^i = y_j with exact Gaussian elimination.
+func SolveLinearSystem(points []Point, k int) ([]*big.Rat, error) {
+	coeffs, _, err := SolveLinearSystemWithDeterminant(points, k)
+	return coeffs, err
+}
+
+// SolveLinearSystemWithDeterminant is SolveLinearSystem that also returns
+// the determinant of the Vandermonde matrix, an integer since every x is,
+// as the signed product of the elimination pivots. Its size shows how
+// well separated the x-coordinates are; when it is zero the matrix is
+// singular and the error says so.
+func SolveLinearSystemWithDeterminant(points []Point, k int) ([]*big.Rat, *big.Int, error) {
+	if k < 1 {
+		return nil, nil, fmt.Errorf("k must be at least 1, got %d", k)
+	}
+	if len(points) < k {
+		return nil, nil, fmt.Errorf("not enough points provided: need %d, got %d", k, len(points))
+	}
+	points = points[:k]
+
+	// Augmented matrix [V | y], one row per point.
+	m := make([][]*big.Rat, k)
+	for r, p := range points {
+		row := make([]*big.Rat, k+1)
+		pow := big.NewInt(1)
+		for c := 0; c < k; c++ {
+			row[c] = new(big.Rat).SetInt(pow)
+			pow = new(big.Int).Mul(pow, p.X)
+		}
+		row[k] = new(big.Rat).SetInt(p.Y)
+		m[r] = row
+	}
+
+	det := big.NewRat(1, 1)
+	for col := 0; col < k; col++ {
+		pivot := col
+		for pivot < k && m[pivot][col].Sign() == 0 {
+			pivot++
+		}
+		if pivot == k {
+			return nil, new(big.Int), fmt.Errorf("singular Vandermonde matrix: x-coordinates are not distinct")
+		}
+		if pivot != col {
+			m[col], m[pivot] = m[pivot], m[col]
+			det.Neg(det)
+		}
+		det.Mul(det, m[col][col])
+
+		for r := 0; r < k; r++ {
+			if r == col || m[r][col].Sign() == 0 {
+				continue
+			}
+			factor := new(big.Rat).Quo(m[r][col], m[col][col])
+			for c := col; c <= k; c++ {
+				m[r][c].Sub(m[r][c], new(big.Rat).Mul(factor, m[col][c]))
+			}
+		}
+	}
+
+	coeffs := make([]*big.Rat, k)
+	for i := range coeffs {
+		coeffs[i] = new(big.Rat).Quo(m[i][k], m[i][i])
+	}
+	return coeffs, new(big.Int).Set(det.Num()), nil
+}
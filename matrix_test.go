@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVandermondeDeterminant(t *testing.T) {
+	// The Vandermonde determinant is the product of x_j - x_i over i < j.
+	tests := []struct {
+		xs   []int64
+		want int64
+	}{
+		{[]int64{1, 2, 3}, 2},
+		{[]int64{1, 3, 4}, 6},
+		{[]int64{2, 1}, -1},
+		{[]int64{1, 2, 3, 4, 5, 6, 7}, 24883200},
+		{[]int64{5}, 1},
+	}
+	for _, tt := range tests {
+		points := polyPoints([]int64{3, 2}, tt.xs...)
+		coeffs, det, err := SolveLinearSystemWithDeterminant(points, len(points))
+		if err != nil {
+			t.Errorf("x = %v: %v", tt.xs, err)
+			continue
+		}
+		if det.Int64() != tt.want {
+			t.Errorf("x = %v: determinant %s, want %d", tt.xs, det.String(), tt.want)
+		}
+		// A single point fits only the constant polynomial through it.
+		if len(tt.xs) > 1 && coeffs[0].RatString() != "3" {
+			t.Errorf("x = %v: constant term %s, want 3", tt.xs, coeffs[0].RatString())
+		}
+	}
+
+	points := polyPoints([]int64{3, 2}, 1, 2, 2)
+	if _, _, err := SolveLinearSystemWithDeterminant(points, 3); err == nil || !strings.Contains(err.Error(), "singular") {
+		t.Errorf("repeated x: got %v, want a singular matrix error", err)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// MergeShares combines two share sets that are expected to overlap, such as
+// copies of the same shares from redundant stores. The union is returned in
+// x order. Where both sets hold a share with the same x the y-values must
+// match; if they do not, a's share is kept and its index in the union is
+// reported as a conflict, since one of the sources is likely corrupt.
+// A duplicate x within a single set is an error.
+func MergeShares(a, b []Point) ([]Point, []int, error) {
+	byX := make(map[string]int) // x in decimal -> index into merged
+	var merged []Point
+	for _, p := range a {
+		key := p.X.String()
+		if _, dup := byX[key]; dup {
+			return nil, nil, fmt.Errorf("duplicate x-coordinate %s in the first share set", key)
+		}
+		byX[key] = len(merged)
+		merged = append(merged, p)
+	}
+
+	conflicting := make(map[string]bool)
+	seenInB := make(map[string]bool)
+	for _, p := range b {
+		key := p.X.String()
+		if seenInB[key] {
+			return nil, nil, fmt.Errorf("duplicate x-coordinate %s in the second share set", key)
+		}
+		seenInB[key] = true
+		if i, ok := byX[key]; ok {
+			if merged[i].Y.Cmp(p.Y) != 0 {
+				conflicting[key] = true
+			}
+			continue
+		}
+		byX[key] = len(merged)
+		merged = append(merged, p)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].X.Cmp(merged[j].X) < 0 })
+	conflicts := []int{}
+	for i, p := range merged {
+		if conflicting[p.X.String()] {
+			conflicts = append(conflicts, i)
+		}
+	}
+	return merged, conflicts, nil
+}
+
+// loadShareFiles reads every file as part of one share set, such as the
+// share_X.json files written by gen --split-output-dir, and returns the
+// shares merged in x order. The files must all declare the same n and k,
+// and a share found in two files must have the same y in both.
+func loadShareFiles(files []string) (KeyInfo, []Point, error) {
+	var keys KeyInfo
+	var points []Point
+	for i, file := range files {
+		fileKeys, rawData, orderedKeys, err := readTestCase(file)
+		if err != nil {
+			return KeyInfo{}, nil, err
+		}
+		if i == 0 {
+			keys = fileKeys
+		} else if fileKeys.N != keys.N || fileKeys.K != keys.K {
+			return KeyInfo{}, nil, fmt.Errorf("%s declares n=%d, k=%d but %s declares n=%d, k=%d", file, fileKeys.N, fileKeys.K, files[0], keys.N, keys.K)
+		}
+
+		var shares []Point
+		seen := make(map[string]string)
+		for _, keyStr := range orderedKeys {
+			x, ok := new(big.Int).SetString(keyStr, 10)
+			if !ok {
+				return KeyInfo{}, nil, fmt.Errorf("failed to parse x-coordinate '%s' in %s to integer", keyStr, file)
+			}
+			if err := checkBits("x-coordinate", keyStr, x); err != nil {
+				return KeyInfo{}, nil, err
+			}
+			if err := checkDuplicateX(seen, keyStr, x); err != nil {
+				return KeyInfo{}, nil, fmt.Errorf("%s: %w", file, err)
+			}
+			share, err := decodeShare(keyStr, rawData[keyStr])
+			if err != nil {
+				return KeyInfo{}, nil, fmt.Errorf("%s: %w", file, err)
+			}
+			share.X = x
+			shares = append(shares, share)
+		}
+
+		merged, conflicts, err := MergeShares(points, shares)
+		if err != nil {
+			return KeyInfo{}, nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if len(conflicts) > 0 {
+			return KeyInfo{}, nil, fmt.Errorf("%s: the share at x=%s differs from one in an earlier file", file, merged[conflicts[0]].X.String())
+		}
+		points = merged
+	}
+
+	if len(points) < keys.K {
+		return KeyInfo{}, nil, fmt.Errorf("not enough points provided: need %d, got %d", keys.K, len(points))
+	}
+	return keys, points, nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestMergeShares(t *testing.T) {
+	a := polyPoints([]int64{3, 2}, 1, 2, 3)
+	b := polyPoints([]int64{3, 2}, 4, 2)
+
+	merged, conflicts, err := MergeShares(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var xs []int64
+	for _, p := range merged {
+		xs = append(xs, p.X.Int64())
+	}
+	if !reflect.DeepEqual(xs, []int64{1, 2, 3, 4}) {
+		t.Errorf("merged x-coordinates = %v, want [1 2 3 4]", xs)
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("conflicts = %v, want none", conflicts)
+	}
+
+	b[1].Y = big.NewInt(8)
+	merged, conflicts, err = MergeShares(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(conflicts, []int{1}) {
+		t.Errorf("conflicts = %v, want [1], the share at x=2", conflicts)
+	}
+	if merged[1].Y.Int64() != 7 {
+		t.Errorf("merged y at x=2 = %s, want 7 from the first set", merged[1].Y.String())
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Method selects the interpolation algorithm Solve uses.
+type Method string
+
+const (
+	// MethodAuto picks between the rational Lagrange path and the
+	// integer-only path by k (see intPathThreshold).
+	MethodAuto Method = "auto"
+	// MethodLagrange sums the Lagrange basis terms over big.Rat.
+	MethodLagrange Method = "lagrange"
+	// MethodNewton evaluates the Newton divided-difference form at 0.
+	MethodNewton Method = "newton"
+	// MethodMatrix solves the Vandermonde system for the coefficients.
+	MethodMatrix Method = "matrix"
+)
+
+// methods lists the accepted --method values in the order shown in help.
+var methods = []Method{MethodAuto, MethodLagrange, MethodNewton, MethodMatrix}
+
+// parseMethod validates a --method value.
+func parseMethod(s string) (Method, error) {
+	for _, m := range methods {
+		if string(m) == s {
+			return m, nil
+		}
+	}
+	names := make([]string, len(methods))
+	for i, m := range methods {
+		names[i] = string(m)
+	}
+	return "", fmt.Errorf("unknown method %q: want one of %s", s, strings.Join(names, ", "))
+}
+
+// Solve reconstructs the secret from the first k points with the given
+// method. All methods are exact, so on valid input they agree.
+func Solve(points []Point, k int, method Method) (*big.Int, error) {
+	switch method {
+	case MethodAuto, "":
+		if k >= intPathThreshold {
+			return SolveInt(points, k)
+		}
+		return Solve(points, k, MethodLagrange)
+	case MethodLagrange:
+		secret, _, err := SolveWithTrace(points, k)
+		return secret, err
+	case MethodNewton:
+		return SolveNewton(points, k)
+	case MethodMatrix:
+		coeffs, err := SolveLinearSystem(points, k)
+		if err != nil {
+			return nil, err
+		}
+		return integerSecret(coeffs[0])
+	}
+	return nil, fmt.Errorf("unknown method %q", method)
+}
+
+// integerSecret returns f(0) as an integer, or the usual error if the
+// interpolation did not produce one.
+func integerSecret(sum *big.Rat) (*big.Int, error) {
+	if !sum.IsInt() {
+		return nil, fmt.Errorf("fatal: final result is not an integer, something went wrong with the calculation. Result: %s", sum.FloatString(5))
+	}
+	return new(big.Int).Set(sum.Num()), nil
+}
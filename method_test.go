@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestMethodsAgree(t *testing.T) {
+	points := randomPoints(7, 6, 8)
+	want, _, err := SolveWithTrace(points, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, method := range []Method{MethodAuto, MethodLagrange, MethodNewton, MethodMatrix} {
+		got, err := Solve(points, 6, method)
+		if err != nil {
+			t.Errorf("Solve(%s): %v", method, err)
+			continue
+		}
+		if got.Cmp(want) != 0 {
+			t.Errorf("Solve(%s) = %s, want %s", method, got.String(), want.String())
+		}
+	}
+	if _, err := parseMethod("simplex"); err == nil {
+		t.Error("parseMethod accepted an unknown method")
+	}
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// outputBase is the base secrets are printed in, set by --output-base.
+var outputBase = 10
+
+// secretWidth is the minimum width secrets are zero-padded to, set by
+// --secret-width. Zero means no padding.
+var secretWidth = 0
+
+// Result is the outcome of reconstructing the secret of one test case file.
+type Result struct {
+	File   string
+	N      int
+	K      int
+	Secret *big.Int
+	// Fraction holds the secret instead of Secret when it is not an
+	// integer, which only happens with --rational-x.
+	Fraction *big.Rat
+	// SecretBytes holds the secret as a byte string, leading zeros
+	// included, when it was reconstructed byte-wise over GF(256).
+	SecretBytes []byte
+	// Confidence is the fraction of k-subsets that agreed on Secret. It is
+	// only set when the secret was found by consensus.
+	Confidence *float64
+	// Explanation is the Lagrange derivation, set only with --explain-json.
+	Explanation *Explanation
+	// Evaluations holds (x, f(x)) for every x requested with --eval-range.
+	Evaluations []Point
+	// InputSHA256 is the hex SHA-256 of the raw input file, formatting
+	// included, so a secret can be traced to the exact bytes it came from.
+	// It is empty when the shares did not come from a file.
+	InputSHA256 string
+	// ContinuedFraction holds the expansion of the secret requested with
+	// --continued-fraction.
+	ContinuedFraction []*big.Int
+	// Determinant is the Vandermonde determinant of the first k
+	// x-coordinates, set only with --show-determinant.
+	Determinant *big.Int
+	// RoundedFrom is the exact value the secret was rounded from under
+	// --round-tolerance, or nil if no rounding was needed.
+	RoundedFrom *big.Rat
+	// Message is the text unpacked from the secret with
+	// --unpack-length-prefix, or nil without it.
+	Message *string
+	// Expected is the comparison against the sidecar file from
+	// --expected-dir, or nil if there was none.
+	Expected *Expectation
+}
+
+// resultJSON is the wire form of a Result, with the secret as a decimal string.
+type resultJSON struct {
+	File        string   `json:"file"`
+	N           int      `json:"n"`
+	K           int      `json:"k"`
+	Secret      string   `json:"secret"`
+	Confidence  *float64 `json:"confidence,omitempty"`
+	Evaluations []Point  `json:"evaluations,omitempty"`
+	RoundedFrom string   `json:"rounded_from,omitempty"`
+	Continued   []string `json:"continued_fraction,omitempty"`
+	Determinant string   `json:"determinant,omitempty"`
+	Message     *string  `json:"message,omitempty"`
+	InputSHA256 string   `json:"input_sha256,omitempty"`
+	Expected    string   `json:"expected,omitempty"`
+	Check       string   `json:"check,omitempty"`
+}
+
+// MarshalJSON encodes the result with its secret as a decimal string.
+func (r Result) MarshalJSON() ([]byte, error) {
+	out := resultJSON{
+		File:        r.File,
+		N:           r.N,
+		K:           r.K,
+		Secret:      r.secretString(),
+		Confidence:  r.Confidence,
+		Evaluations: r.Evaluations,
+		Message:     r.Message,
+		InputSHA256: r.InputSHA256,
+	}
+	if r.RoundedFrom != nil {
+		out.RoundedFrom = r.RoundedFrom.RatString()
+	}
+	if r.Determinant != nil {
+		out.Determinant = r.Determinant.String()
+	}
+	for _, t := range r.ContinuedFraction {
+		out.Continued = append(out.Continued, t.String())
+	}
+	if r.Expected != nil {
+		out.Expected = r.Expected.Want
+		out.Check = r.Expected.status()
+	}
+	return json.Marshal(out)
+}
+
+// secretString formats the secret in outputBase, as "a/b" for a fraction,
+// or in hex for a byte string, zero-padded to secretWidth.
+// The base has already been validated, so formatting cannot fail.
+func (r Result) secretString() string {
+	return padSecret(r.unpaddedSecret())
+}
+
+// unpaddedSecret is secretString without the --secret-width padding.
+func (r Result) unpaddedSecret() string {
+	format := func(n *big.Int) string {
+		s, _ := FormatBase(n, outputBase)
+		return s
+	}
+	if r.SecretBytes != nil {
+		return hex.EncodeToString(r.SecretBytes)
+	}
+	if r.Fraction != nil {
+		if r.Fraction.IsInt() {
+			return format(r.Fraction.Num())
+		}
+		return format(r.Fraction.Num()) + "/" + format(r.Fraction.Denom())
+	}
+	return format(r.Secret)
+}
+
+// padSecret left-pads s with zeros to secretWidth characters, keeping a
+// leading minus sign in front.
+func padSecret(s string) string {
+	if len(s) >= secretWidth {
+		return s
+	}
+	pad := strings.Repeat("0", secretWidth-len(s))
+	if strings.HasPrefix(s, "-") {
+		return "-" + pad + s[1:]
+	}
+	return pad + s
+}
+
+// checkSecretWidth reports an error if the secret is already wider than
+// --secret-width, since padding cannot make it fit.
+func (r Result) checkSecretWidth() error {
+	if secretWidth == 0 {
+		return nil
+	}
+	if s := r.unpaddedSecret(); len(s) > secretWidth {
+		return fmt.Errorf("secret %s is %d characters wide, wider than --secret-width %d", s, len(s), secretWidth)
+	}
+	return nil
+}
+
+// resultTemplate replaces the usual text line for each result when set by
+// --template.
+var resultTemplate *template.Template
+
+// templateData is what --template sees: the result with its secret already
+// formatted as it would be printed.
+type templateData struct {
+	File       string
+	N          int
+	K          int
+	Secret     string
+	Confidence *float64
+}
+
+// SetResultTemplate parses s as a text/template for each result line,
+// with the fields of templateData. A trailing newline is added if s has
+// none. It also executes the template once on an empty result, so that a
+// misspelt field is reported up front rather than on the first file.
+func SetResultTemplate(s string) error {
+	if !strings.HasSuffix(s, "\n") {
+		s += "\n"
+	}
+	t, err := template.New("result").Option("missingkey=error").Parse(s)
+	if err != nil {
+		return err
+	}
+	if err := t.Execute(io.Discard, templateData{}); err != nil {
+		return err
+	}
+	resultTemplate = t
+	return nil
+}
+
+// secretValue returns the secret as a number for ordering, whichever form
+// it was reconstructed in.
+func (r Result) secretValue() *big.Rat {
+	switch {
+	case r.SecretBytes != nil:
+		return new(big.Rat).SetInt(new(big.Int).SetBytes(r.SecretBytes))
+	case r.Fraction != nil:
+		return r.Fraction
+	}
+	return new(big.Rat).SetInt(r.Secret)
+}
+
+// sortResultsBySecret orders results by numeric secret, breaking ties by
+// file name.
+func sortResultsBySecret(results []Result) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if c := results[i].secretValue().Cmp(results[j].secretValue()); c != 0 {
+			return c < 0
+		}
+		return results[i].File < results[j].File
+	})
+}
+
+// printResult writes the human-readable line for a result to w.
+func printResult(w io.Writer, r Result) error {
+	if resultTemplate != nil {
+		data := templateData{File: r.File, N: r.N, K: r.K, Secret: r.secretString(), Confidence: r.Confidence}
+		if err := resultTemplate.Execute(w, data); err != nil {
+			return fmt.Errorf("failed to render --template for %s: %w", r.File, err)
+		}
+		return nil
+	}
+	var suffix string
+	if r.Confidence != nil {
+		suffix += fmt.Sprintf(" (confidence %.2f)", *r.Confidence)
+	}
+	if r.RoundedFrom != nil {
+		suffix += fmt.Sprintf(" (rounded from %s)", r.RoundedFrom.RatString())
+	}
+	if r.Determinant != nil {
+		suffix += fmt.Sprintf(" (Vandermonde determinant %s)", r.Determinant.String())
+	}
+	if r.ContinuedFraction != nil {
+		suffix += " = " + formatContinuedFraction(r.ContinuedFraction)
+	}
+	if r.Message != nil {
+		suffix += fmt.Sprintf(" %q", *r.Message)
+	}
+	if r.Expected != nil {
+		if r.Expected.OK {
+			suffix += " [OK]"
+		} else {
+			suffix += fmt.Sprintf(" [MISMATCH: expected %s]", r.Expected.Want)
+		}
+	}
+	_, err := fmt.Fprintf(w, "Secret for %s: %s%s\n", r.File, r.secretString(), suffix)
+	return err
+}
+
+// Failure records a file that could not be solved.
+type Failure struct {
+	File string
+	Err  error
+}
+
+// MarshalJSON encodes the failure as
+// {"file":"...","category":"...","error":"..."}.
+func (f Failure) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		File     string `json:"file"`
+		Category string `json:"category"`
+		Error    string `json:"error"`
+	}{f.File, f.category(), f.Err.Error()})
+}
+
+// inputError marks a failure to read or decode an input file, as opposed
+// to one while reconstructing or checking its secret.
+type inputError struct{ err error }
+
+func (e inputError) Error() string { return e.err.Error() }
+func (e inputError) Unwrap() error { return e.err }
+
+// category classifies the failure: "io" if the input could not be read,
+// "input" if it could not be parsed or decoded, and "solve" for anything
+// that went wrong after that.
+func (f Failure) category() string {
+	var pathErr *fs.PathError
+	var inErr inputError
+	switch {
+	case errors.As(f.Err, &pathErr):
+		return "io"
+	case errors.As(f.Err, &inErr):
+		return "input"
+	}
+	return "solve"
+}
+
+// writeErrorFile writes failures to path as a JSON array, which is empty
+// rather than null when nothing failed.
+func writeErrorFile(path string, failures []Failure) error {
+	if failures == nil {
+		failures = []Failure{}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(f, failures); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Summary is the final tally of a batch run.
+type Summary struct {
+	Total    int       `json:"total"`
+	Solved   int       `json:"solved"`
+	Failed   int       `json:"failed"`
+	Failures []Failure `json:"failures"`
+	Stats    *Stats    `json:"stats,omitempty"`
+}
+
+// printSummary writes the human-readable tally of a batch run to w.
+func printSummary(w io.Writer, s Summary) {
+	fmt.Fprintf(w, "Summary: %d total, %d solved, %d failed\n", s.Total, s.Solved, s.Failed)
+	for _, f := range s.Failures {
+		fmt.Fprintf(w, "  FAILED %s: %v\n", f.File, f.Err)
+	}
+	if s.Stats != nil {
+		printStats(w, s.Stats)
+	}
+}
+
+// writeResultsJSON writes results to w as an indented JSON array.
+func writeResultsJSON(w io.Writer, results []Result) error {
+	if results == nil {
+		results = []Result{}
+	}
+	return writeJSON(w, results)
+}
+
+// writeJSON writes v to w as indented JSON.
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
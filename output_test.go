@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// withTemplate installs s as --template for the rest of the test.
+func withTemplate(t *testing.T, s string) {
+	t.Helper()
+	if err := SetResultTemplate(s); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resultTemplate = nil })
+}
+
+func TestResultTemplate(t *testing.T) {
+	withTemplate(t, "{{.File}},{{.K}}/{{.N}},{{.Secret}}")
+	var buf bytes.Buffer
+	if err := printResult(&buf, Result{File: "a.json", N: 4, K: 3, Secret: big.NewInt(42)}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "a.json,3/4,42\n"; got != want {
+		t.Errorf("rendered %q, want %q", got, want)
+	}
+}
+
+func TestResultTemplateExecError(t *testing.T) {
+	// Confidence is nil when parsed, so only a real result reaches .Foo.
+	withTemplate(t, "{{if .Confidence}}{{.Confidence.Foo}}{{end}}")
+	confidence := 0.5
+	r := Result{File: "a.json", Secret: big.NewInt(1), Confidence: &confidence}
+	if err := printResult(&bytes.Buffer{}, r); err == nil {
+		t.Fatal("printResult swallowed a template execution error")
+	}
+}
+
+func TestSortResultsBySecret(t *testing.T) {
+	results := []Result{
+		{File: "c.json", Secret: big.NewInt(100)},
+		{File: "b.json", Secret: big.NewInt(-5)},
+		{File: "a.json", Fraction: big.NewRat(7, 2)},
+		{File: "d.json", SecretBytes: []byte{0x01, 0x00}},
+		{File: "a2.json", Secret: big.NewInt(100)},
+	}
+	sortResultsBySecret(results)
+	var got []string
+	for _, r := range results {
+		got = append(got, r.File)
+	}
+	want := []string{"b.json", "a.json", "a2.json", "c.json", "d.json"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+}
+
+func TestErrorFile(t *testing.T) {
+	good := writeFile(t, "good.json", `{
+		"keys": {"n": 2, "k": 2},
+		"1": {"base": "10", "value": "5"},
+		"2": {"base": "10", "value": "7"}
+	}`)
+	bad := writeFile(t, "bad.json", `{
+		"keys": {"n": 2, "k": 2},
+		"1": {"base": "10", "value": "5"}
+	}`)
+	errorFile := filepath.Join(t.TempDir(), "errors.json")
+
+	stdout, _, code := runCLI(t, "--format", "json", "--error-file", errorFile, good, bad)
+	if code != 1 {
+		t.Errorf("exit code %d, want 1 for a failed file", code)
+	}
+	var results []struct {
+		File   string `json:"file"`
+		Secret string `json:"secret"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &results); err != nil {
+		t.Fatalf("stdout is not a JSON array of results: %v\n%s", err, stdout)
+	}
+	if len(results) != 1 || results[0].File != good || results[0].Secret != "3" {
+		t.Errorf("results = %+v, want only %s with secret 3", results, good)
+	}
+
+	data, err := os.ReadFile(errorFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var failures []struct {
+		File     string `json:"file"`
+		Category string `json:"category"`
+		Error    string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &failures); err != nil {
+		t.Fatalf("error file is not a JSON array: %v\n%s", err, data)
+	}
+	if len(failures) != 1 || failures[0].File != bad || failures[0].Category != "input" || !strings.Contains(failures[0].Error, "not enough points") {
+		t.Errorf("failures = %+v, want only %s with a not-enough-points input error", failures, bad)
+	}
+}
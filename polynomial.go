@@ -0,0 +1,298 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// dividedDifferences returns the Newton coefficients of the polynomial
+// through points, i.e. the top edge of the divided-difference table:
+// c[j] = f[x_0, ..., x_j].
+func dividedDifferences(points []Point) ([]*big.Rat, error) {
+	n := len(points)
+	c := make([]*big.Rat, n)
+	for i, p := range points {
+		c[i] = new(big.Rat).SetInt(p.Y)
+	}
+
+	for j := 1; j < n; j++ {
+		for i := n - 1; i >= j; i-- {
+			dx := new(big.Int).Sub(points[i].X, points[i-j].X)
+			if dx.Sign() == 0 {
+				return nil, fmt.Errorf("duplicate x-coordinate %s", points[i].X.String())
+			}
+			c[i].Sub(c[i], c[i-1])
+			c[i].Quo(c[i], new(big.Rat).SetInt(dx))
+		}
+	}
+	return c, nil
+}
+
+// MinimalDegree returns the smallest k such that every point lies on a
+// single polynomial of degree k-1.
+//
+// The Newton form fitted to the first d+1 points passes through all of them
+// exactly when every higher-order divided difference is zero, so the answer
+// is one past the last non-zero Newton coefficient.
+func MinimalDegree(points []Point) (int, error) {
+	if len(points) == 0 {
+		return 0, fmt.Errorf("no points provided")
+	}
+
+	c, err := dividedDifferences(points)
+	if err != nil {
+		return 0, err
+	}
+
+	k := 1
+	for j := len(c) - 1; j > 0; j-- {
+		if c[j].Sign() != 0 {
+			k = j + 1
+			break
+		}
+	}
+	return k, nil
+}
+
+// Polynomial is a polynomial with integer coefficients, constant term first.
+type Polynomial struct {
+	Coeffs []*big.Int
+}
+
+// Evaluate returns the value of the polynomial at x.
+func (p *Polynomial) Evaluate(x *big.Int) *big.Int {
+	return evalCoefficients(p.Coeffs, x, nil)
+}
+
+// ReconstructPolynomial recovers the degree-(k-1) polynomial through the
+// first k points. It fits the Newton form and expands it into monomial
+// coefficients, which must all come out as integers.
+func ReconstructPolynomial(points []Point, k int) (*Polynomial, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("k must be at least 1, got %d", k)
+	}
+	if len(points) < k {
+		return nil, fmt.Errorf("not enough points provided: need %d, got %d", k, len(points))
+	}
+	points = points[:k]
+
+	c, err := dividedDifferences(points)
+	if err != nil {
+		return nil, err
+	}
+
+	// Horner's rule on the Newton form:
+	// P = c[k-1]; P = P*(x - x_j) + c[j] for j = k-2 .. 0.
+	coeffs := []*big.Rat{new(big.Rat).Set(c[k-1])}
+	for j := k - 2; j >= 0; j-- {
+		xj := new(big.Rat).SetInt(points[j].X)
+		next := make([]*big.Rat, len(coeffs)+1)
+		for i := range next {
+			next[i] = new(big.Rat)
+		}
+		for i, a := range coeffs {
+			next[i+1].Add(next[i+1], a)
+			next[i].Sub(next[i], new(big.Rat).Mul(a, xj))
+		}
+		next[0].Add(next[0], c[j])
+		coeffs = next
+	}
+
+	poly := &Polynomial{Coeffs: make([]*big.Int, len(coeffs))}
+	for i, a := range coeffs {
+		if !a.IsInt() {
+			return nil, fmt.Errorf("coefficient of x^%d is not an integer: %s", i, a.RatString())
+		}
+		poly.Coeffs[i] = new(big.Int).Set(a.Num())
+	}
+	return poly, nil
+}
+
+// lagrangeEval returns the value at x of the polynomial through all of
+// points, computed exactly over the rationals.
+func lagrangeEval(points []Point, x *big.Int) (*big.Rat, error) {
+	sum := new(big.Rat)
+	for j := range points {
+		numerator := new(big.Int).Set(points[j].Y)
+		denominator := big.NewInt(1)
+		for i := range points {
+			if i == j {
+				continue
+			}
+			diff := new(big.Int).Sub(points[j].X, points[i].X)
+			if diff.Sign() == 0 {
+				return nil, fmt.Errorf("duplicate x-coordinate %s", points[i].X.String())
+			}
+			numerator.Mul(numerator, new(big.Int).Sub(x, points[i].X))
+			denominator.Mul(denominator, diff)
+		}
+		sum.Add(sum, new(big.Rat).SetFrac(numerator, denominator))
+	}
+	return sum, nil
+}
+
+// VerifyShares checks every point after the first k against the polynomial
+// through the first k and returns the indices of those that do not lie on it.
+func VerifyShares(points []Point, k int) ([]int, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("k must be at least 1, got %d", k)
+	}
+	if len(points) < k {
+		return nil, fmt.Errorf("not enough points provided: need %d, got %d", k, len(points))
+	}
+
+	var bad []int
+	for i := k; i < len(points); i++ {
+		fx, err := lagrangeEval(points[:k], points[i].X)
+		if err != nil {
+			return nil, err
+		}
+		if !fx.IsInt() || fx.Num().Cmp(points[i].Y) != 0 {
+			bad = append(bad, i)
+		}
+	}
+	return bad, nil
+}
+
+// requireVerified succeeds only when there is at least one redundant share
+// and every redundant share agrees with the polynomial through the first k.
+func requireVerified(points []Point, k int) error {
+	if len(points) <= k {
+		return fmt.Errorf("cannot verify: only %d shares for k=%d, so there is no redundant share to check against", len(points), k)
+	}
+	bad, err := VerifyShares(points, k)
+	if err != nil {
+		return err
+	}
+	if len(bad) > 0 {
+		xs := make([]string, len(bad))
+		for i, j := range bad {
+			xs[i] = points[j].X.String()
+		}
+		return fmt.Errorf("verification failed: shares at x=%s do not lie on the reconstructed polynomial", strings.Join(xs, ", "))
+	}
+	return nil
+}
+
+// SolveNewton reconstructs the secret from the first k points by building
+// the Newton divided-difference form and evaluating it at x = 0.
+func SolveNewton(points []Point, k int) (*big.Int, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("k must be at least 1, got %d", k)
+	}
+	if len(points) < k {
+		return nil, fmt.Errorf("not enough points provided: need %d, got %d", k, len(points))
+	}
+	points = points[:k]
+
+	c, err := dividedDifferences(points)
+	if err != nil {
+		return nil, err
+	}
+
+	// f(0) = c[0] + (0-x_0)(c[1] + (0-x_1)(c[2] + ...))
+	sum := new(big.Rat).Set(c[k-1])
+	for j := k - 2; j >= 0; j-- {
+		sum.Mul(sum, new(big.Rat).SetInt(new(big.Int).Neg(points[j].X)))
+		sum.Add(sum, c[j])
+	}
+	return integerSecret(sum)
+}
+
+// SolveWithResiduals reconstructs the secret from the first k points and
+// returns, for every point, its residual y_i - f(x_i) against the
+// polynomial through those k. Consistent shares have a zero residual, so
+// the non-zero ones show which shares are off and by how much. It errors
+// if f(x_i) is not an integer, which happens only when the shares
+// disagree badly enough that no integer residual exists.
+func SolveWithResiduals(points []Point, k int) (*big.Int, []*big.Int, error) {
+	secret, _, err := SolveWithTrace(points, k)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	residuals := make([]*big.Int, len(points))
+	for i, p := range points {
+		fx, err := lagrangeEval(points[:k], p.X)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !fx.IsInt() {
+			return nil, nil, fmt.Errorf("f(%s) = %s is not an integer, so the share there has no integer residual", p.X.String(), fx.RatString())
+		}
+		residuals[i] = new(big.Int).Sub(p.Y, fx.Num())
+	}
+	return secret, residuals, nil
+}
+
+// ChartPoint pairs a share's given y with the value the reconstructed
+// polynomial takes at its x. The two are equal for a consistent share. It
+// is an alias so that SolveChartData returns a plain struct slice.
+type ChartPoint = struct {
+	X, GivenY, FX *big.Int
+}
+
+// SolveChartData reconstructs the polynomial through the first k points and
+// returns its secret, the polynomial itself, and f(x) next to the given y
+// at every input point, which is what a consistency chart plots.
+//
+// Like ReconstructPolynomial it errors when the polynomial does not have
+// integer coefficients, since neither a Polynomial nor an integer f(x)
+// could hold it; SolveLinearSystem gives the rational coefficients then.
+func SolveChartData(points []Point, k int) (*big.Int, *Polynomial, []struct{ X, GivenY, FX *big.Int }, error) {
+	poly, err := ReconstructPolynomial(points, k)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	chart := make([]ChartPoint, len(points))
+	for i, p := range points {
+		chart[i] = ChartPoint{X: p.X, GivenY: p.Y, FX: poly.Evaluate(p.X)}
+	}
+	return new(big.Int).Set(poly.Coeffs[0]), poly, chart, nil
+}
+
+// ShareAdjustment is a new y-value for the share at Index.
+type ShareAdjustment struct {
+	Index int
+	NewY  *big.Int
+}
+
+// MinimalChangeToTarget finds every way to make reconstruction from the
+// first k points yield target by changing a single share. Since
+// f(0) = Σ y_j L_j(0), raising y_j by d moves the secret by d * L_j(0), so
+// share j needs d = (target - secret) / L_j(0), which must be an integer.
+// Shares whose basis value does not divide the gap cannot reach target on
+// their own and are left out; an empty result with no error means no
+// single share can, or that the secret is already target. Points after
+// the first k do not affect the secret and are never adjusted.
+func MinimalChangeToTarget(points []Point, k int, target *big.Int) ([]ShareAdjustment, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("k must be at least 1, got %d", k)
+	}
+	if len(points) < k {
+		return nil, fmt.Errorf("not enough points provided: need %d, got %d", k, len(points))
+	}
+	terms, err := lagrangeTerms(points[:k])
+	if err != nil {
+		return nil, err
+	}
+
+	adjustments := []ShareAdjustment{}
+	gap := new(big.Rat).Sub(new(big.Rat).SetInt(target), terms[len(terms)-1].Sum)
+	if gap.Sign() == 0 {
+		return adjustments, nil
+	}
+	for j, t := range terms {
+		if t.Basis.Sign() == 0 {
+			continue
+		}
+		d := new(big.Rat).Quo(gap, t.Basis)
+		if !d.IsInt() {
+			continue
+		}
+		adjustments = append(adjustments, ShareAdjustment{Index: j, NewY: new(big.Int).Add(points[j].Y, d.Num())})
+	}
+	return adjustments, nil
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// polyPoints returns the points (x, f(x)) of the polynomial with the given
+// integer coefficients, constant term first, at each x.
+func polyPoints(coeffs []int64, xs ...int64) []Point {
+	points := make([]Point, len(xs))
+	for i, x := range xs {
+		y := new(big.Int)
+		for j := len(coeffs) - 1; j >= 0; j-- {
+			y.Mul(y, big.NewInt(x))
+			y.Add(y, big.NewInt(coeffs[j]))
+		}
+		points[i] = Point{X: big.NewInt(x), Y: y}
+	}
+	return points
+}
+
+func TestMinimalDegree(t *testing.T) {
+	tests := []struct {
+		coeffs []int64
+		want   int
+	}{
+		{[]int64{7}, 1},
+		{[]int64{3, 2}, 2},
+		{[]int64{1, 0, 1}, 3},
+		{[]int64{5, -4, 0, 2}, 4},
+	}
+	for _, tt := range tests {
+		got, err := MinimalDegree(polyPoints(tt.coeffs, 1, 2, 3, 4, 5, 6))
+		if err != nil {
+			t.Errorf("MinimalDegree(%v): %v", tt.coeffs, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("MinimalDegree(%v) = %d, want %d", tt.coeffs, got, tt.want)
+		}
+	}
+}
+
+func TestReconstructPolynomialRoundTrip(t *testing.T) {
+	coeffs := []*big.Int{big.NewInt(42), big.NewInt(-5), big.NewInt(7), big.NewInt(3)}
+	points := SharesFromCoefficients(coeffs, 6, nil)
+
+	poly, err := ReconstructPolynomial(points, len(coeffs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(poly.Coeffs) != len(coeffs) {
+		t.Fatalf("recovered %d coefficients, want %d", len(poly.Coeffs), len(coeffs))
+	}
+	for i, c := range coeffs {
+		if poly.Coeffs[i].Cmp(c) != 0 {
+			t.Errorf("coefficient %d = %s, want %s", i, poly.Coeffs[i].String(), c.String())
+		}
+	}
+	// Every share, including the two beyond the first k, lies on it.
+	for _, p := range points {
+		if fx := poly.Evaluate(p.X); fx.Cmp(p.Y) != 0 {
+			t.Errorf("f(%s) = %s, want %s", p.X.String(), fx.String(), p.Y.String())
+		}
+	}
+}
+
+func TestRequireVerified(t *testing.T) {
+	coeffs := []int64{3, 2, 1}
+	if err := requireVerified(polyPoints(coeffs, 1, 2, 3), 3); err == nil {
+		t.Error("requireVerified passed with n == k, where nothing can be confirmed")
+	}
+	if err := requireVerified(polyPoints(coeffs, 1, 2, 3, 4, 5), 3); err != nil {
+		t.Errorf("requireVerified on consistent shares: %v", err)
+	}
+	points := polyPoints(coeffs, 1, 2, 3, 4, 5)
+	points[4].Y = new(big.Int).Add(points[4].Y, big.NewInt(1))
+	if err := requireVerified(points, 3); err == nil {
+		t.Error("requireVerified passed with a flipped redundant share")
+	}
+}
+
+func TestSolveWithResiduals(t *testing.T) {
+	points := polyPoints([]int64{3, 2, 1}, 1, 2, 3, 4, 5)
+	points[3].Y = new(big.Int).Add(points[3].Y, big.NewInt(4))
+
+	secret, residuals, err := SolveWithResiduals(points, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Int64() != 3 {
+		t.Errorf("secret = %s, want 3", secret.String())
+	}
+	for i, r := range residuals {
+		want := int64(0)
+		if i == 3 {
+			want = 4
+		}
+		if r.Int64() != want {
+			t.Errorf("residual[%d] = %s, want %d", i, r.String(), want)
+		}
+	}
+}
+
+func TestSolveChartData(t *testing.T) {
+	// f(x) = 3 + 2x + x^2, with the redundant share at x=4 off by 5.
+	points := polyPoints([]int64{3, 2, 1}, 1, 2, 3, 4)
+	points[3].Y = new(big.Int).Add(points[3].Y, big.NewInt(5))
+
+	secret, poly, chart, err := SolveChartData(points, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Int64() != 3 {
+		t.Errorf("secret = %s, want 3", secret.String())
+	}
+	for i, want := range []int64{3, 2, 1} {
+		if poly.Coeffs[i].Int64() != want {
+			t.Errorf("coefficient %d = %s, want %d", i, poly.Coeffs[i].String(), want)
+		}
+	}
+	want := [][3]int64{{1, 6, 6}, {2, 11, 11}, {3, 18, 18}, {4, 32, 27}}
+	if len(chart) != len(want) {
+		t.Fatalf("chart has %d points, want %d", len(chart), len(want))
+	}
+	for i, c := range chart {
+		got := [3]int64{c.X.Int64(), c.GivenY.Int64(), c.FX.Int64()}
+		if got != want[i] {
+			t.Errorf("chart[%d] = (x, y, f(x)) %v, want %v", i, got, want[i])
+		}
+	}
+}
+
+func TestSolveChartDataNonInteger(t *testing.T) {
+	// The line through (1, 1) and (3, 2) is (x + 1) / 2.
+	points := []Point{
+		{X: big.NewInt(1), Y: big.NewInt(1)},
+		{X: big.NewInt(3), Y: big.NewInt(2)},
+	}
+	_, _, _, err := SolveChartData(points, 2)
+	if err == nil || !strings.Contains(err.Error(), "not an integer") {
+		t.Errorf("err = %v, want a non-integer coefficient error", err)
+	}
+}
+
+func TestMinimalChangeToTarget(t *testing.T) {
+	// At x = 1, 2, 3 the Lagrange basis values at 0 are 3, -3 and 1.
+	points := polyPoints([]int64{3, 2, 1}, 1, 2, 3, 4)
+	tests := []struct {
+		target  int64
+		indices []int
+	}{
+		{9, []int{0, 1, 2}},
+		{4, []int{2}},
+		{3, nil},
+	}
+	for _, tt := range tests {
+		adjustments, err := MinimalChangeToTarget(points, 3, big.NewInt(tt.target))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(adjustments) != len(tt.indices) {
+			t.Errorf("target %d: %d adjustments, want %d", tt.target, len(adjustments), len(tt.indices))
+			continue
+		}
+		for i, adj := range adjustments {
+			if adj.Index != tt.indices[i] {
+				t.Errorf("target %d: adjustment %d changes share %d, want %d", tt.target, i, adj.Index, tt.indices[i])
+			}
+			changed := append([]Point(nil), points...)
+			changed[adj.Index] = Point{X: points[adj.Index].X, Y: adj.NewY}
+			secret, err := Solve(changed, 3, MethodLagrange)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if secret.Int64() != tt.target {
+				t.Errorf("target %d: share %d set to %s gives %s", tt.target, adj.Index, adj.NewY.String(), secret.String())
+			}
+		}
+	}
+}
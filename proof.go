@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Proof records how a secret was reconstructed, so that someone else
+// holding the same shares can recompute it without redoing the selection.
+type Proof struct {
+	// X lists the x-coordinates of the shares used, in decimal.
+	X      []string `json:"x"`
+	Method Method   `json:"method"`
+	Secret string   `json:"secret"`
+}
+
+// SolveWithProof reconstructs the secret from the first k points with
+// MethodLagrange and returns a Proof naming the shares it used.
+func SolveWithProof(points []Point, k int) (*big.Int, Proof, error) {
+	secret, err := Solve(points, k, MethodLagrange)
+	if err != nil {
+		return nil, Proof{}, err
+	}
+	proof := Proof{Method: MethodLagrange, Secret: secret.String()}
+	for _, p := range points[:k] {
+		proof.X = append(proof.X, p.X.String())
+	}
+	return secret, proof, nil
+}
+
+// VerifyProof picks the shares named by p out of points, reconstructs the
+// secret from them with p's method, and reports whether it matches. It
+// errors if a named share is missing or the proof is malformed.
+func VerifyProof(points []Point, p Proof) (bool, error) {
+	if len(p.X) == 0 {
+		return false, fmt.Errorf("proof names no shares")
+	}
+	want, ok := new(big.Int).SetString(p.Secret, 10)
+	if !ok {
+		return false, fmt.Errorf("invalid secret '%s' in proof", p.Secret)
+	}
+
+	byX := make(map[string]Point, len(points))
+	for _, pt := range points {
+		byX[pt.X.String()] = pt
+	}
+	selected := make([]Point, len(p.X))
+	for i, x := range p.X {
+		n, ok := new(big.Int).SetString(x, 10)
+		if !ok {
+			return false, fmt.Errorf("invalid x-coordinate '%s' in proof", x)
+		}
+		pt, ok := byX[n.String()]
+		if !ok {
+			return false, fmt.Errorf("no share at x=%s", x)
+		}
+		selected[i] = pt
+	}
+
+	got, err := Solve(selected, len(selected), p.Method)
+	if err != nil {
+		return false, err
+	}
+	return got.Cmp(want) == 0, nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestProofRoundTrip(t *testing.T) {
+	points := polyPoints([]int64{3, 2, 1}, 4, 1, 5, 2)
+	secret, proof, err := SolveWithProof(points, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Int64() != 3 {
+		t.Fatalf("secret = %s, want 3", secret.String())
+	}
+
+	// The proof survives JSON, and verifies against the shares in any order.
+	data, err := json.Marshal(proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Proof
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	shuffled := []Point{points[3], points[2], points[1], points[0]}
+	if ok, err := VerifyProof(shuffled, decoded); err != nil || !ok {
+		t.Errorf("VerifyProof = %v, %v, want true", ok, err)
+	}
+
+	// L(0) for the share at x=4 is -5/3, so adding 3 keeps the secret an
+	// integer but moves it by -5.
+	points[0].Y = new(big.Int).Add(points[0].Y, big.NewInt(3))
+	if ok, err := VerifyProof(points, decoded); err != nil || ok {
+		t.Errorf("VerifyProof with a changed share = %v, %v, want false", ok, err)
+	}
+	if _, err := VerifyProof(points[1:], decoded); err == nil {
+		t.Error("VerifyProof succeeded without the share at x=4")
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// RatPoint is a point whose coordinates may be fractions, for schemes that
+// place shares at non-integer x-coordinates such as x = 1/2.
+type RatPoint struct {
+	X *big.Rat
+	Y *big.Rat
+}
+
+// SolveRat interpolates through the first k points entirely over the
+// rationals and returns f(0), which need not be an integer.
+func SolveRat(points []RatPoint, k int) (*big.Rat, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("k must be at least 1, got %d", k)
+	}
+	if len(points) < k {
+		return nil, fmt.Errorf("not enough points provided: need %d, got %d", k, len(points))
+	}
+	points = points[:k]
+
+	// f(0) = Σ y_j * Π x_i / (x_i - x_j), exactly as in the integer path.
+	sum := new(big.Rat)
+	for j := range points {
+		term := new(big.Rat).Set(points[j].Y)
+		for i := range points {
+			if i == j {
+				continue
+			}
+			diff := new(big.Rat).Sub(points[i].X, points[j].X)
+			if diff.Sign() == 0 {
+				return nil, fmt.Errorf("duplicate x-coordinate %s", points[i].X.RatString())
+			}
+			term.Mul(term, points[i].X)
+			term.Quo(term, diff)
+		}
+		sum.Add(sum, term)
+	}
+	return sum, nil
+}
+
+// roundNear rounds r to the nearest integer, halves rounding up, and
+// reports whether r lies within tol of it.
+func roundNear(r, tol *big.Rat) (*big.Int, bool) {
+	half := big.NewRat(1, 2)
+	shifted := new(big.Rat).Add(r, half)
+	n := new(big.Int).Div(shifted.Num(), shifted.Denom()) // floor, as Denom > 0
+	diff := new(big.Rat).Sub(r, new(big.Rat).SetInt(n))
+	return n, diff.Abs(diff).Cmp(tol) <= 0
+}
+
+// ContinuedFraction returns the terms [a0; a1, ..., an] of the finite
+// continued-fraction expansion of r, with a0 = floor(r) and every later
+// term positive. An integer has the single term [r].
+func ContinuedFraction(r *big.Rat) []*big.Int {
+	num := new(big.Int).Set(r.Num())
+	den := new(big.Int).Set(r.Denom())
+	var terms []*big.Int
+	for den.Sign() != 0 {
+		// Euclidean division, so a0 is the floor even for negative r.
+		q, m := new(big.Int).DivMod(num, den, new(big.Int))
+		terms = append(terms, q)
+		num, den = den, m
+	}
+	return terms
+}
+
+// formatContinuedFraction writes terms in the usual [a0; a1, a2] notation.
+func formatContinuedFraction(terms []*big.Int) string {
+	var b strings.Builder
+	b.WriteString("[")
+	for i, t := range terms {
+		switch i {
+		case 0:
+		case 1:
+			b.WriteString("; ")
+		default:
+			b.WriteString(", ")
+		}
+		b.WriteString(t.String())
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+// loadRatTestCase is loadTestCaseDigest for files whose keys may be fractions,
+// written as "1/2" or "0.5".
+func loadRatTestCase(filePath string) (KeyInfo, []RatPoint, string, error) {
+	jsonData, err := readTestFile(filePath)
+	if err != nil {
+		return KeyInfo{}, nil, "", err
+	}
+	keys, rawData, sortedKeys, err := parseTestCase(jsonData, filePath)
+	if err != nil {
+		return KeyInfo{}, nil, "", err
+	}
+
+	var points []RatPoint
+	// "1/2", "2/4" and "0.5" are all the same x.
+	seen := make(map[string]string)
+	for _, keyStr := range sortedKeys {
+		x, ok := new(big.Rat).SetString(keyStr)
+		if !ok {
+			return KeyInfo{}, nil, "", fmt.Errorf("failed to parse x-coordinate '%s' to a rational", keyStr)
+		}
+		if err := checkBits("x-coordinate numerator", keyStr, x.Num()); err != nil {
+			return KeyInfo{}, nil, "", err
+		}
+		if err := checkBits("x-coordinate denominator", keyStr, x.Denom()); err != nil {
+			return KeyInfo{}, nil, "", err
+		}
+		if err := checkDuplicateX(seen, keyStr, x); err != nil {
+			return KeyInfo{}, nil, "", err
+		}
+
+		share, err := decodeShare(keyStr, rawData[keyStr])
+		if err != nil {
+			return KeyInfo{}, nil, "", err
+		}
+
+		points = append(points, RatPoint{X: x, Y: new(big.Rat).SetInt(share.Y)})
+	}
+
+	if len(points) < keys.K {
+		return KeyInfo{}, nil, "", fmt.Errorf("not enough points provided: need %d, got %d", keys.K, len(points))
+	}
+
+	return keys, points, hashBytes(jsonData), nil
+}
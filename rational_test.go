@@ -0,0 +1,105 @@
+package main
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestSolveRatFractionalX(t *testing.T) {
+	// The line through (1/2, 1) and (3/2, 2) is y = x + 1/2.
+	file := writeFile(t, "rat.json", `{
+		"keys": {"n": 3, "k": 2},
+		"1/2": {"base": "10", "value": "1"},
+		"1.5": {"base": "10", "value": "2"},
+		"5/2": {"base": "10", "value": "3"}
+	}`)
+	keys, points, _, err := loadRatTestCase(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := SolveRat(points, keys.K)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := secret.RatString(); got != "1/2" {
+		t.Fatalf("secret = %s, want 1/2", got)
+	}
+}
+
+func TestLoadRatDuplicateX(t *testing.T) {
+	// "1/2" and "0.5" are the same x even though neither is among the
+	// first k keys once sorted.
+	file := writeFile(t, "dup.json", `{
+		"keys": {"n": 4, "k": 2},
+		"1/4": {"base": "10", "value": "1"},
+		"1/3": {"base": "10", "value": "2"},
+		"1/2": {"base": "10", "value": "3"},
+		"0.5": {"base": "10", "value": "4"}
+	}`)
+	_, _, _, err := loadRatTestCase(file)
+	if err == nil || !strings.Contains(err.Error(), "duplicate x-coordinate 1/2") {
+		t.Fatalf("loadRatTestCase = %v, want a duplicate x-coordinate error", err)
+	}
+}
+
+func TestLoadRatMaxBits(t *testing.T) {
+	withMaxBits(t, 64)
+	file := writeFile(t, "big.json", `{
+		"keys": {"n": 2, "k": 2},
+		"1/2": {"base": "10", "value": "1"},
+		"1/100000000000000000000000000000": {"base": "10", "value": "2"}
+	}`)
+	_, _, _, err := loadRatTestCase(file)
+	if err == nil || !strings.Contains(err.Error(), "--max-bits") {
+		t.Fatalf("loadRatTestCase = %v, want a --max-bits error for the denominator", err)
+	}
+}
+
+func TestRoundTolerance(t *testing.T) {
+	// The line through (1, 42) and (1000000001, 43) has slope 1e-9, so
+	// f(0) = 41.999999999.
+	file := writeFile(t, "near.json", `{
+		"keys": {"n": 2, "k": 2},
+		"1": {"base": "10", "value": "42"},
+		"1000000001": {"base": "10", "value": "43"}
+	}`)
+	tol := func(s string) *big.Rat {
+		r, _ := new(big.Rat).SetString(s)
+		return r
+	}
+
+	result, err := solveFile(file, &options{load: loadTestCaseDigest, roundTolerance: tol("1e-6")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Secret.Int64() != 42 {
+		t.Errorf("rounded secret = %s, want 42", result.Secret.String())
+	}
+	if result.RoundedFrom == nil || result.RoundedFrom.Cmp(tol("41.999999999")) != 0 {
+		t.Errorf("RoundedFrom = %v, want 41.999999999", result.RoundedFrom)
+	}
+
+	_, err = solveFile(file, &options{load: loadTestCaseDigest, roundTolerance: tol("1e-12")})
+	if err == nil || !strings.Contains(err.Error(), "--round-tolerance") {
+		t.Errorf("tolerance 1e-12: got %v, want an out-of-tolerance error", err)
+	}
+}
+
+func TestContinuedFraction(t *testing.T) {
+	tests := []struct {
+		r, want string
+	}{
+		{"415/93", "[4; 2, 6, 7]"},
+		{"-7/3", "[-3; 1, 2]"},
+		{"1/2", "[0; 2]"},
+		{"42", "[42]"},
+		{"0", "[0]"},
+	}
+	for _, tt := range tests {
+		r, _ := new(big.Rat).SetString(tt.r)
+		if got := formatContinuedFraction(ContinuedFraction(r)); got != tt.want {
+			t.Errorf("ContinuedFraction(%s) = %s, want %s", tt.r, got, tt.want)
+		}
+	}
+}
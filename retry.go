@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"syscall"
+	"time"
+)
+
+// readRetries is how many extra attempts readFileRetry makes after a failed
+// read, and retryDelay the pause before the first; it doubles after each.
+// They are set by --retry and --retry-delay.
+var (
+	readRetries = 0
+	retryDelay  = 100 * time.Millisecond
+)
+
+// readFileRetry is os.ReadFile with up to readRetries retries, for inputs
+// on flaky network mounts.
+func readFileRetry(path string) ([]byte, error) {
+	return withRetry(func() ([]byte, error) { return os.ReadFile(path) }, readRetries, retryDelay)
+}
+
+// withRetry calls read until it succeeds or has failed retries+1 times,
+// sleeping delay before the first retry and twice as long before each one
+// after. A permanent error is reported at once (see permanentReadError).
+// Only I/O is retried; callers parse the data afterwards, so parse errors
+// are never retried.
+func withRetry(read func() ([]byte, error), retries int, delay time.Duration) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		data, err := read()
+		if err == nil || attempt >= retries || permanentReadError(err) {
+			return data, err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// permanentReadError reports whether err is one that waiting will not
+// fix: a missing file, a permission error, or a path that is a directory.
+func permanentReadError(err error) bool {
+	return errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) ||
+		errors.Is(err, fs.ErrInvalid) || errors.Is(err, syscall.EISDIR)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWithRetry(t *testing.T) {
+	calls := 0
+	flaky := func() ([]byte, error) {
+		calls++
+		if calls <= 2 {
+			return nil, fmt.Errorf("transient failure %d", calls)
+		}
+		return []byte("ok"), nil
+	}
+	data, err := withRetry(flaky, 3, 0)
+	if err != nil || string(data) != "ok" || calls != 3 {
+		t.Errorf("withRetry = %q, %v after %d calls, want ok after 3", data, err, calls)
+	}
+
+	calls = 0
+	if _, err := withRetry(flaky, 1, 0); err == nil || calls != 2 {
+		t.Errorf("one retry: got %v after %d calls, want the second failure after 2", err, calls)
+	}
+
+	calls = 0
+	missing := func() ([]byte, error) {
+		calls++
+		return nil, fs.ErrNotExist
+	}
+	if _, err := withRetry(missing, 3, 0); !errors.Is(err, fs.ErrNotExist) || calls != 1 {
+		t.Errorf("missing file: got %v after %d calls, want ErrNotExist after 1", err, calls)
+	}
+
+	calls = 0
+	denied := func() ([]byte, error) {
+		calls++
+		return nil, &fs.PathError{Op: "open", Path: "secret.json", Err: fs.ErrPermission}
+	}
+	if _, err := withRetry(denied, 3, 0); !errors.Is(err, fs.ErrPermission) || calls != 1 {
+		t.Errorf("permission error: got %v after %d calls, want ErrPermission after 1", err, calls)
+	}
+}
+
+func TestReadFileRetryPermanent(t *testing.T) {
+	// With retries a second apart, a retried read would take seconds.
+	readRetries, retryDelay = 3, time.Second
+	defer func() { readRetries, retryDelay = 0, 100*time.Millisecond }()
+
+	dir := t.TempDir()
+	for _, path := range []string{filepath.Join(dir, "missing.json"), dir} {
+		start := time.Now()
+		if _, err := readFileRetry(path); err == nil {
+			t.Errorf("readFileRetry(%s) succeeded", path)
+		}
+		if elapsed := time.Since(start); elapsed >= retryDelay {
+			t.Errorf("readFileRetry(%s) took %v, want it to fail without retrying", path, elapsed)
+		}
+	}
+}
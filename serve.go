@@ -0,0 +1,167 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// schemaHeader lets a client name the schema version of its request body.
+// A top-level "version" field does the same; the header wins if both are set.
+const schemaHeader = "X-Shamir-Schema"
+
+// defaultSchema is assumed when a request names no version.
+const defaultSchema = "1"
+
+// maxRequestBody bounds the size of a posted test case.
+const maxRequestBody = 10 << 20
+
+// schemaDecoders maps each supported schema version to the function that
+// decodes a request body written in it.
+var schemaDecoders = map[string]func(body []byte) (KeyInfo, []Point, error){
+	"1": decodeSchemaV1,
+}
+
+// decodeSchemaV1 decodes the original test case format, ignoring the
+// optional top-level "version" field.
+func decodeSchemaV1(body []byte) (KeyInfo, []Point, error) {
+	keys, rawData, orderedKeys, err := parseTestCase(body, "request")
+	if err != nil {
+		return KeyInfo{}, nil, err
+	}
+	shareKeys := orderedKeys[:0:0]
+	for _, key := range orderedKeys {
+		if key != "version" {
+			shareKeys = append(shareKeys, key)
+		}
+	}
+	points, err := decodePoints(keys, rawData, shareKeys)
+	if err != nil {
+		return KeyInfo{}, nil, err
+	}
+	return keys, points, nil
+}
+
+// supportedSchemas returns the supported schema versions in sorted order.
+func supportedSchemas() []string {
+	versions := make([]string, 0, len(schemaDecoders))
+	for v := range schemaDecoders {
+		versions = append(versions, v)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// requestSchema returns the schema version named by the request header or,
+// failing that, by the body's "version" field.
+func requestSchema(r *http.Request, body []byte) string {
+	if v := r.Header.Get(schemaHeader); v != "" {
+		return v
+	}
+	var probe struct {
+		Version json.RawMessage `json:"version"`
+	}
+	if json.Unmarshal(body, &probe) == nil && probe.Version != nil {
+		// Accept both "version": "1" and "version": 1.
+		return strings.Trim(string(probe.Version), `"`)
+	}
+	return defaultSchema
+}
+
+// resultCache holds recent /solve results, so that identical requests are
+// not solved again. runServe sizes it from --cache-size.
+var resultCache = newSolveCache(0)
+
+// handleSolve reconstructs the secret of the test case posted as the body.
+func handleSolve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeHTTPError(w, http.StatusMethodNotAllowed, "use POST")
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	if err != nil {
+		writeHTTPError(w, http.StatusBadRequest, fmt.Sprintf("failed to read request body: %v", err))
+		return
+	}
+
+	version := requestSchema(r, body)
+	decode, ok := schemaDecoders[version]
+	if !ok {
+		writeHTTPError(w, http.StatusBadRequest, fmt.Sprintf("unsupported schema version %q; supported versions: %s", version, strings.Join(supportedSchemas(), ", ")))
+		return
+	}
+
+	// The schema is part of the key, since the same body could in
+	// principle decode differently under another version.
+	sum := sha256.Sum256(body)
+	cacheKey := version + ":" + hex.EncodeToString(sum[:])
+	if secret, ok := resultCache.get(cacheKey); ok {
+		writeHTTPJSON(w, http.StatusOK, map[string]string{"secret": secret, "schema": version})
+		return
+	}
+
+	keys, points, err := decode(body)
+	if err != nil {
+		writeHTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	secret, _, err := SolveWithTrace(points, keys.K)
+	if err != nil {
+		writeHTTPError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	resultCache.put(cacheKey, secret.String())
+	writeHTTPJSON(w, http.StatusOK, map[string]string{"secret": secret.String(), "schema": version})
+}
+
+// handleMetrics reports the result cache counters.
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	hits, misses, entries := resultCache.stats()
+	writeHTTPJSON(w, http.StatusOK, map[string]any{
+		"cache_hits":    hits,
+		"cache_misses":  misses,
+		"cache_entries": entries,
+		"cache_size":    resultCache.size,
+	})
+}
+
+// handleVersions lists the schema versions /solve accepts.
+func handleVersions(w http.ResponseWriter, r *http.Request) {
+	writeHTTPJSON(w, http.StatusOK, map[string]any{"versions": supportedSchemas(), "default": defaultSchema})
+}
+
+func writeHTTPJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeHTTPError(w http.ResponseWriter, status int, msg string) {
+	writeHTTPJSON(w, status, map[string]string{"error": msg})
+}
+
+// runServe implements the "serve" subcommand, an HTTP front end to the
+// solver.
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "listen `address`")
+	cacheSize := fs.Int("cache-size", 1024, "number of /solve results to cache (0 disables the cache)")
+	fs.Parse(args)
+
+	resultCache = newSolveCache(*cacheSize)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/solve", handleSolve)
+	mux.HandleFunc("/versions", handleVersions)
+	mux.HandleFunc("/metrics", handleMetrics)
+
+	log.Printf("listening on %s", *addr)
+	return http.ListenAndServe(*addr, mux)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSolveCacheHit(t *testing.T) {
+	resultCache = newSolveCache(8)
+	defer func() { resultCache = newSolveCache(0) }()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/solve", handleSolve)
+	mux.HandleFunc("/metrics", handleMetrics)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	body := `{"keys": {"n": 2, "k": 2}, "1": {"base": "10", "value": "5"}, "2": {"base": "10", "value": "7"}}`
+	for i := 0; i < 2; i++ {
+		resp, err := http.Post(srv.URL+"/solve", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got map[string]string
+		err = json.NewDecoder(resp.Body).Decode(&got)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK || got["secret"] != "3" {
+			t.Fatalf("request %d: status %d, body %v, want secret 3", i+1, resp.StatusCode, got)
+		}
+	}
+
+	resp, err := http.Get(srv.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var metrics struct {
+		Hits    uint64 `json:"cache_hits"`
+		Misses  uint64 `json:"cache_misses"`
+		Entries int    `json:"cache_entries"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&metrics); err != nil {
+		t.Fatal(err)
+	}
+	if metrics.Hits != 1 || metrics.Misses != 1 || metrics.Entries != 1 {
+		t.Errorf("metrics = %+v, want one hit, one miss and one entry", metrics)
+	}
+}
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// ResultSink receives solved results one at a time. Emit is called once
+// per result in output order and Finish once after the last, so a sink can
+// either write as it goes or collect everything and write at the end.
+type ResultSink interface {
+	Emit(r *Result) error
+	Finish() error
+}
+
+// A sink that also implements FailureSink is told about each file that
+// could not be solved, in the same order as the results.
+type FailureSink interface {
+	Fail(f Failure) error
+}
+
+// A sink that also implements SummarySink receives the tally of the run
+// once, after the last result and before Finish.
+type SummarySink interface {
+	Summarize(s Summary)
+}
+
+// A sink that also implements ProgressSink is told the name of each file
+// before it is solved.
+type ProgressSink interface {
+	Start(file string)
+}
+
+// Run solves each file as configured by opts and passes every result to
+// sink, then its summary, and then finishes it. A file that cannot be
+// solved stops the run without calling Finish, unless
+// opts.continueOnError is set; the failure is then passed on and the run
+// goes on with the next file. The returned summary covers every file that
+// was attempted.
+func Run(files []string, opts *options, sink ResultSink) (Summary, error) {
+	var recorder *statsRecorder
+	if opts.stats {
+		recorder = startStats()
+	}
+	summary := Summary{Total: len(files), Failures: []Failure{}}
+	progress, _ := sink.(ProgressSink)
+	failures, _ := sink.(FailureSink)
+	for _, file := range files {
+		if progress != nil {
+			progress.Start(file)
+		}
+		result, err := processFile(file, opts)
+		if err != nil {
+			if !opts.continueOnError {
+				return summary, fmt.Errorf("error processing %s: %w", file, err)
+			}
+			failure := Failure{File: file, Err: err}
+			summary.Failures = append(summary.Failures, failure)
+			summary.Failed++
+			if failures != nil {
+				if err := failures.Fail(failure); err != nil {
+					return summary, fmt.Errorf("error writing results: %w", err)
+				}
+			}
+			continue
+		}
+		summary.Solved++
+		if err := sink.Emit(&result); err != nil {
+			return summary, fmt.Errorf("error writing results: %w", err)
+		}
+	}
+
+	if recorder != nil {
+		summary.Stats = recorder.finish(len(files))
+	}
+	if s, ok := sink.(SummarySink); ok {
+		s.Summarize(summary)
+	}
+	if err := sink.Finish(); err != nil {
+		return summary, fmt.Errorf("error writing results: %w", err)
+	}
+	return summary, nil
+}
+
+// processFile is solveFile followed by the conversions and checks that
+// opts asks for on each result.
+func processFile(file string, opts *options) (Result, error) {
+	result, err := solveFile(file, opts)
+	if err != nil {
+		return Result{}, err
+	}
+	if opts.continuedFraction {
+		result.ContinuedFraction = ContinuedFraction(result.secretValue())
+	}
+	if opts.intWidth != 0 {
+		if err := result.applyIntWidth(opts.intWidth); err != nil {
+			return Result{}, err
+		}
+	}
+	if err := result.checkSecretWidth(); err != nil {
+		return Result{}, err
+	}
+	if opts.unpackLengthPrefix {
+		message, err := result.unpackMessage()
+		if err != nil {
+			return Result{}, err
+		}
+		result.Message = &message
+	}
+	if opts.expectedDir != "" {
+		if err := checkExpected(opts.expectedDir, &result); err != nil {
+			return Result{}, err
+		}
+	}
+	return result, nil
+}
+
+// textSink writes the human-readable line for each result, followed by its
+// --eval-range table if it has one, and the --stats line on Finish.
+type textSink struct {
+	w     io.Writer
+	stats *Stats
+}
+
+func (s *textSink) Emit(r *Result) error {
+	if err := printResult(s.w, *r); err != nil {
+		return err
+	}
+	if r.Evaluations != nil {
+		return printEvaluations(s.w, r.Evaluations)
+	}
+	return nil
+}
+
+func (s *textSink) Summarize(sum Summary) { s.stats = sum.Stats }
+
+func (s *textSink) Finish() error {
+	if s.stats != nil {
+		printStats(s.w, s.stats)
+	}
+	return nil
+}
+
+// jsonSink collects results and writes them as one JSON array on Finish,
+// or as {"results": [...], "stats": {...}} when the run has stats.
+type jsonSink struct {
+	w       io.Writer
+	results []Result
+	stats   *Stats
+}
+
+func (s *jsonSink) Emit(r *Result) error {
+	s.results = append(s.results, *r)
+	return nil
+}
+
+func (s *jsonSink) Summarize(sum Summary) { s.stats = sum.Stats }
+
+func (s *jsonSink) Finish() error {
+	if s.stats == nil {
+		return writeResultsJSON(s.w, s.results)
+	}
+	results := s.results
+	if results == nil {
+		results = []Result{}
+	}
+	return writeJSON(s.w, map[string]any{"results": results, "stats": s.stats})
+}
+
+// summarySink writes only the final tally, for --summary-only.
+type summarySink struct {
+	w       io.Writer
+	json    bool
+	summary Summary
+}
+
+func (s *summarySink) Emit(r *Result) error { return nil }
+
+func (s *summarySink) Summarize(sum Summary) { s.summary = sum }
+
+func (s *summarySink) Finish() error {
+	if s.json {
+		return writeJSON(s.w, s.summary)
+	}
+	printSummary(s.w, s.summary)
+	return nil
+}
+
+// explainSink collects the Lagrange derivation of each result and writes
+// them as one JSON array on Finish, for --explain-json.
+type explainSink struct {
+	w            io.Writer
+	explanations []*Explanation
+	stats        *Stats
+}
+
+func (s *explainSink) Emit(r *Result) error {
+	s.explanations = append(s.explanations, r.Explanation)
+	return nil
+}
+
+func (s *explainSink) Summarize(sum Summary) { s.stats = sum.Stats }
+
+func (s *explainSink) Finish() error {
+	explanations := s.explanations
+	if explanations == nil {
+		explanations = []*Explanation{}
+	}
+	if s.stats == nil {
+		return writeJSON(s.w, explanations)
+	}
+	return writeJSON(s.w, map[string]any{"explanations": explanations, "stats": s.stats})
+}
+
+// dirSink groups outcomes by directory, for --recursive. As text it
+// writes a heading before each directory's results and its tally after
+// them; as JSON it writes every group and the summary on Finish.
+type dirSink struct {
+	w       io.Writer
+	json    bool
+	groups  dirGroups
+	current *DirGroup
+	summary Summary
+}
+
+// enter switches to the group for file, closing the previous group's text.
+func (s *dirSink) enter(file string) *DirGroup {
+	group := s.groups.get(file)
+	if group != s.current && !s.json {
+		if s.current != nil {
+			printDirSummary(s.w, s.current)
+		}
+		printDirHeader(s.w, group.Dir)
+	}
+	s.current = group
+	return group
+}
+
+func (s *dirSink) Emit(r *Result) error {
+	group := s.enter(r.File)
+	group.Results = append(group.Results, *r)
+	group.Solved++
+	if s.json {
+		return nil
+	}
+	return (&textSink{w: s.w}).Emit(r)
+}
+
+func (s *dirSink) Fail(f Failure) error {
+	group := s.enter(f.File)
+	group.Failures = append(group.Failures, f)
+	group.Failed++
+	return nil
+}
+
+func (s *dirSink) Summarize(sum Summary) { s.summary = sum }
+
+func (s *dirSink) Finish() error {
+	if s.json {
+		return writeJSON(s.w, struct {
+			Directories map[string]*DirGroup `json:"directories"`
+			Summary     Summary              `json:"summary"`
+		}{s.groups.groups, s.summary})
+	}
+	if s.current != nil {
+		printDirSummary(s.w, s.current)
+	}
+	fmt.Fprintln(s.w)
+	printSummary(s.w, s.summary)
+	return nil
+}
+
+// failureLog writes each failure to w as it happens, then passes it on to
+// the wrapped sink, so that failures show on stderr while results go to
+// stdout.
+type failureLog struct {
+	w    io.Writer
+	next ResultSink
+}
+
+func (s *failureLog) Emit(r *Result) error { return s.next.Emit(r) }
+
+func (s *failureLog) Fail(f Failure) error {
+	fmt.Fprintf(s.w, "Error processing %s: %v\n", f.File, f.Err)
+	return passFailure(s.next, f)
+}
+
+func (s *failureLog) Summarize(sum Summary) { passSummary(s.next, sum) }
+
+func (s *failureLog) Finish() error { return s.next.Finish() }
+
+// deferredSink holds every result back until Finish and then passes them
+// on, sorted by secret if bySecret is set. Failures and the summary are
+// passed on at once.
+type deferredSink struct {
+	next     ResultSink
+	bySecret bool
+	results  []Result
+}
+
+func (s *deferredSink) Emit(r *Result) error {
+	s.results = append(s.results, *r)
+	return nil
+}
+
+func (s *deferredSink) Fail(f Failure) error { return passFailure(s.next, f) }
+
+func (s *deferredSink) Summarize(sum Summary) { passSummary(s.next, sum) }
+
+func (s *deferredSink) Finish() error {
+	if s.bySecret {
+		sortResultsBySecret(s.results)
+	}
+	for i := range s.results {
+		if err := s.next.Emit(&s.results[i]); err != nil {
+			return err
+		}
+	}
+	return s.next.Finish()
+}
+
+// progressSink draws the --tui progress line while the run goes on, and
+// clears it before the wrapped sink finishes. Failures are passed on with
+// the line cleared, so a failureLog inside it writes on a blank line and
+// the progress is redrawn below. Results must be held back until Finish,
+// or they would be drawn over.
+type progressSink struct {
+	tui  *progressTUI
+	next ResultSink
+}
+
+func (s *progressSink) Start(file string) { s.tui.working(file) }
+
+func (s *progressSink) Emit(r *Result) error {
+	s.tui.done(true)
+	return s.next.Emit(r)
+}
+
+func (s *progressSink) Fail(f Failure) error {
+	s.tui.clear()
+	err := passFailure(s.next, f)
+	s.tui.done(false)
+	return err
+}
+
+func (s *progressSink) Summarize(sum Summary) { passSummary(s.next, sum) }
+
+func (s *progressSink) Finish() error {
+	s.tui.finish()
+	return s.next.Finish()
+}
+
+// passFailure hands f to sink if it is a FailureSink.
+func passFailure(sink ResultSink, f Failure) error {
+	if fs, ok := sink.(FailureSink); ok {
+		return fs.Fail(f)
+	}
+	return nil
+}
+
+// passSummary hands sum to sink if it is a SummarySink.
+func passSummary(sink ResultSink, sum Summary) {
+	if ss, ok := sink.(SummarySink); ok {
+		ss.Summarize(sum)
+	}
+}
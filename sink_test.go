@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// memorySink records everything Run passes it.
+type memorySink struct {
+	secrets  []string
+	failed   []string
+	started  []string
+	summary  *Summary
+	finished bool
+}
+
+func (s *memorySink) Start(file string)     { s.started = append(s.started, file) }
+func (s *memorySink) Fail(f Failure) error  { s.failed = append(s.failed, f.File); return nil }
+func (s *memorySink) Summarize(sum Summary) { s.summary = &sum }
+func (s *memorySink) Finish() error         { s.finished = true; return nil }
+
+func (s *memorySink) Emit(r *Result) error {
+	s.secrets = append(s.secrets, r.secretString())
+	return nil
+}
+
+func TestRunMemorySink(t *testing.T) {
+	first := writeFile(t, "first.json", `{
+		"keys": {"n": 2, "k": 2},
+		"1": {"base": "10", "value": "5"},
+		"2": {"base": "10", "value": "7"}
+	}`)
+	bad := writeFile(t, "bad.json", `{
+		"keys": {"n": 2, "k": 2},
+		"1": {"base": "10", "value": "5"}
+	}`)
+	second := writeFile(t, "second.json", `{
+		"keys": {"n": 2, "k": 2},
+		"1": {"base": "16", "value": "c"},
+		"2": {"base": "10", "value": "14"}
+	}`)
+	files := []string{first, bad, second}
+
+	sink := &memorySink{}
+	opts := &options{load: loadTestCaseDigest, method: MethodAuto, continueOnError: true}
+	summary, err := Run(files, opts, sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sink.secrets, []string{"3", "10"}) {
+		t.Errorf("secrets = %v, want [3 10]", sink.secrets)
+	}
+	if !reflect.DeepEqual(sink.failed, []string{bad}) || !reflect.DeepEqual(sink.started, files) {
+		t.Errorf("failed %v and started %v, want [%s] and %v", sink.failed, sink.started, bad, files)
+	}
+	if sink.summary == nil || sink.summary.Solved != 2 || sink.summary.Failed != 1 || !sink.finished {
+		t.Errorf("summary %+v, finished %v, want 2 solved, 1 failed and finished", sink.summary, sink.finished)
+	}
+	if summary.Total != 3 || summary.Failed != 1 {
+		t.Errorf("returned summary %+v, want 3 total and 1 failed", summary)
+	}
+
+	// Without continueOnError the run stops at the failure, unfinished.
+	sink = &memorySink{}
+	opts.continueOnError = false
+	if _, err := Run(files, opts, sink); err == nil {
+		t.Fatal("Run succeeded despite an unsolvable file")
+	}
+	if !reflect.DeepEqual(sink.secrets, []string{"3"}) || sink.finished {
+		t.Errorf("after a failure: secrets %v, finished %v, want [3] and unfinished", sink.secrets, sink.finished)
+	}
+}
+
+func TestProgressSinkClearsLineForFailure(t *testing.T) {
+	var out bytes.Buffer
+	tui := &progressTUI{w: &out, total: 2, start: time.Now()}
+	sink := &progressSink{tui: tui, next: &failureLog{w: &out, next: &memorySink{}}}
+
+	sink.Start("bad.json")
+	if err := sink.Fail(Failure{File: "bad.json", Err: errors.New("boom")}); err != nil {
+		t.Fatal(err)
+	}
+	// The progress line is blanked, the failure printed on it, and the
+	// progress redrawn on the next line.
+	want := regexp.MustCompile(`^\r\[-+\] 0/2 .*bad\.json\r +\rError processing bad\.json: boom\n\r\[#+-+\] 1/2 .* failed 1 `)
+	if !want.MatchString(out.String()) {
+		t.Errorf("output = %q, want the failure on a cleared line", out.String())
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"time"
+)
+
+// Stats summarises the cost of a run, reported with --stats.
+type Stats struct {
+	Files      int           `json:"files"`
+	Wall       time.Duration `json:"-"`
+	WallMS     float64       `json:"wall_ms"`
+	PerFileMS  float64       `json:"per_file_ms"`
+	Mallocs    uint64        `json:"mallocs"`
+	AllocBytes uint64        `json:"alloc_bytes"`
+}
+
+// statsRecorder captures the clock and allocation counters at the start of
+// a run so the difference can be reported at the end.
+type statsRecorder struct {
+	start time.Time
+	mem   runtime.MemStats
+}
+
+func startStats() *statsRecorder {
+	r := &statsRecorder{start: time.Now()}
+	runtime.ReadMemStats(&r.mem)
+	return r
+}
+
+// finish returns the statistics for a run that processed files inputs.
+func (r *statsRecorder) finish(files int) *Stats {
+	wall := time.Since(r.start)
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	s := &Stats{
+		Files:      files,
+		Wall:       wall,
+		WallMS:     float64(wall) / float64(time.Millisecond),
+		Mallocs:    mem.Mallocs - r.mem.Mallocs,
+		AllocBytes: mem.TotalAlloc - r.mem.TotalAlloc,
+	}
+	if files > 0 {
+		s.PerFileMS = s.WallMS / float64(files)
+	}
+	return s
+}
+
+// printStats writes the human-readable stats line to w.
+func printStats(w io.Writer, s *Stats) {
+	perFile := time.Duration(0)
+	if s.Files > 0 {
+		perFile = s.Wall / time.Duration(s.Files)
+	}
+	fmt.Fprintf(w, "Stats: %d files in %v (avg %v per file), %d allocations, %d bytes allocated\n",
+		s.Files, s.Wall.Round(time.Microsecond), perFile.Round(time.Microsecond), s.Mallocs, s.AllocBytes)
+}
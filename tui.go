@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// progressWidth is the number of cells in the --tui progress bar.
+const progressWidth = 30
+
+// progressTUI draws a one-line live view of a batch run, redrawn in place
+// with a carriage return: a progress bar, the counts so far, the elapsed
+// time and the file being solved.
+type progressTUI struct {
+	w              io.Writer
+	total          int
+	solved, failed int
+	start          time.Time
+	lastLen        int
+}
+
+// newProgressTUI returns a progress display for total files, or nil when
+// stdout is not a terminal, in which case output stays plain.
+func newProgressTUI(total int) *progressTUI {
+	if !isTerminal(os.Stdout) {
+		return nil
+	}
+	return &progressTUI{w: os.Stdout, total: total, start: time.Now()}
+}
+
+// isTerminal reports whether f is a character device such as a TTY.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// working shows that file is being solved.
+func (t *progressTUI) working(file string) {
+	t.draw(file)
+}
+
+// done records the outcome of the file just solved.
+func (t *progressTUI) done(ok bool) {
+	if ok {
+		t.solved++
+	} else {
+		t.failed++
+	}
+	t.draw("")
+}
+
+// finish clears the progress line so the final results print cleanly.
+func (t *progressTUI) finish() {
+	t.clear()
+}
+
+// clear blanks the progress line and leaves the cursor at its start, so
+// other output can be written over it; the next draw starts a new line.
+func (t *progressTUI) clear() {
+	fmt.Fprintf(t.w, "\r%s\r", strings.Repeat(" ", t.lastLen))
+	t.lastLen = 0
+}
+
+func (t *progressTUI) draw(file string) {
+	finished := t.solved + t.failed
+	filled := progressWidth
+	if t.total > 0 {
+		filled = progressWidth * finished / t.total
+	}
+	bar := strings.Repeat("#", filled) + strings.Repeat("-", progressWidth-filled)
+	line := fmt.Sprintf("[%s] %d/%d  solved %d  failed %d  %v  %s",
+		bar, finished, t.total, t.solved, t.failed, time.Since(t.start).Round(time.Second/10), file)
+
+	pad := ""
+	if n := len(line); n < t.lastLen {
+		pad = strings.Repeat(" ", t.lastLen-n)
+	}
+	fmt.Fprintf(t.w, "\r%s%s", line, pad)
+	t.lastLen = len(line) + len(pad)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"unicode/utf8"
+)
+
+// UnpackLengthPrefixed decodes a message packed as one length byte followed
+// by that many bytes of UTF-8 text. It errors if the length does not match
+// the bytes that follow or the text is not valid UTF-8.
+func UnpackLengthPrefixed(b []byte) (string, error) {
+	if len(b) == 0 {
+		return "", fmt.Errorf("no length byte: the secret is empty")
+	}
+	length, body := int(b[0]), b[1:]
+	if length != len(body) {
+		return "", fmt.Errorf("length prefix says %d bytes but %d follow", length, len(body))
+	}
+	if !utf8.Valid(body) {
+		return "", fmt.Errorf("message is not valid UTF-8")
+	}
+	return string(body), nil
+}
+
+// unpackMessage applies UnpackLengthPrefixed to the secret's bytes: the
+// reconstructed byte string if there is one, else the big-endian bytes of
+// the integer secret.
+func (r Result) unpackMessage() (string, error) {
+	switch {
+	case r.SecretBytes != nil:
+		return UnpackLengthPrefixed(r.SecretBytes)
+	case r.Secret != nil && r.Secret.Sign() >= 0:
+		return UnpackLengthPrefixed(r.Secret.Bytes())
+	}
+	return "", fmt.Errorf("only a non-negative integer or byte-string secret can be unpacked")
+}
+
+// TwosComplement reinterprets n, the unsigned big-endian value of a
+// secret, as a signed two's-complement integer of the given bit width, so
+// that 0xff with width 8 is -1. n must be non-negative and fit in width
+// bits.
+func TwosComplement(n *big.Int, width int) (*big.Int, error) {
+	if width < 1 {
+		return nil, fmt.Errorf("width must be at least 1 bit, got %d", width)
+	}
+	if n.Sign() < 0 {
+		return nil, fmt.Errorf("secret %s is negative, so it is not a %d-bit pattern", n.String(), width)
+	}
+	if n.BitLen() > width {
+		return nil, fmt.Errorf("secret %s needs %d bits, more than the %d-bit width", n.String(), n.BitLen(), width)
+	}
+	if n.Bit(width-1) == 0 {
+		return new(big.Int).Set(n), nil
+	}
+	return new(big.Int).Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(width))), nil
+}
+
+// applyIntWidth replaces the secret with its signed reading as a width-bit
+// two's-complement integer, for --int-width.
+func (r *Result) applyIntWidth(width int) error {
+	n := r.Secret
+	switch {
+	case r.SecretBytes != nil:
+		n = new(big.Int).SetBytes(r.SecretBytes)
+	case n == nil:
+		return fmt.Errorf("only an integer or byte-string secret can be read as a signed integer")
+	}
+	signed, err := TwosComplement(n, width)
+	if err != nil {
+		return err
+	}
+	r.Secret, r.SecretBytes = signed, nil
+	return nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestUnpackLengthPrefixed(t *testing.T) {
+	msg, err := UnpackLengthPrefixed([]byte("\x05hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg != "hello" {
+		t.Errorf("UnpackLengthPrefixed = %q, want %q", msg, "hello")
+	}
+
+	for _, b := range []string{"\x06hello", "\x04hello", "", "\x02\xff\xfe"} {
+		if msg, err := UnpackLengthPrefixed([]byte(b)); err == nil {
+			t.Errorf("UnpackLengthPrefixed(%q) = %q, want an error", b, msg)
+		}
+	}
+}
+
+func TestTwosComplement(t *testing.T) {
+	tests := []struct {
+		n     string
+		width int
+		want  string
+	}{
+		{"127", 8, "127"},
+		{"255", 8, "-1"},
+		{"128", 8, "-128"},
+		{"32767", 16, "32767"},
+		{"65534", 16, "-2"},
+		{"9223372036854775807", 64, "9223372036854775807"},
+		{"18446744073709551615", 64, "-1"},
+		{"5", 3, "-3"},
+	}
+	for _, tt := range tests {
+		n, _ := new(big.Int).SetString(tt.n, 10)
+		got, err := TwosComplement(n, tt.width)
+		if err != nil {
+			t.Errorf("TwosComplement(%s, %d): %v", tt.n, tt.width, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("TwosComplement(%s, %d) = %s, want %s", tt.n, tt.width, got.String(), tt.want)
+		}
+	}
+
+	if _, err := TwosComplement(big.NewInt(256), 8); err == nil {
+		t.Error("TwosComplement accepted 256 as an 8-bit pattern")
+	}
+	if _, err := TwosComplement(big.NewInt(-1), 8); err == nil {
+		t.Error("TwosComplement accepted a negative pattern")
+	}
+}